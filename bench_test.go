@@ -0,0 +1,144 @@
+package boundednet_test
+
+import (
+	"fmt"
+	bn "github.com/fhltang/boundednet"
+	"github.com/fhltang/boundednet/binary"
+	"github.com/fhltang/boundednet/snoc"
+	"math/rand"
+	"testing"
+)
+
+type distribution struct {
+	Name     string
+	Generate func(r *rand.Rand, n int) []bn.Network
+}
+
+var distributions = []distribution{
+	// Hosts anywhere in the address space.
+	{"uniform", func(r *rand.Rand, n int) []bn.Network {
+		input := make([]bn.Network, n)
+		for i := range input {
+			a := bn.Address(r.Uint32())
+			input[i] = bn.Network{a, a + 1}
+		}
+		return input
+	}},
+
+	// Networks of /24 to /32 packed into a few /16s, like the address
+	// plan of a real organisation.
+	{"clustered", func(r *rand.Rand, n int) []bn.Network {
+		clusters := make([]bn.Address, 16)
+		for i := range clusters {
+			clusters[i] = bn.Address(r.Uint32()) &^ 0xffff
+		}
+		input := make([]bn.Network, n)
+		for i := range input {
+			size := bn.Address(1) << uint(r.Intn(9))
+			left := clusters[r.Intn(len(clusters))] + bn.Address(r.Intn(1<<16))
+			left -= left % size
+			input[i] = bn.Network{left, left + size}
+		}
+		return input
+	}},
+
+	// Evenly spaced hosts, so that every merge adds waste and many
+	// choices tie.
+	{"adversarial", func(r *rand.Rand, n int) []bn.Network {
+		input := make([]bn.Network, n)
+		gap := (bn.Address(1) << 32) / bn.Address(n)
+		for i := range input {
+			a := bn.Address(i) * gap
+			input[i] = bn.Network{a, a + 1}
+		}
+		return input
+	}},
+}
+
+// Benchmark a solver over each distribution for N up to maxN, skipping
+// cases whose estimated cost exceeds 10^9.
+func benchmarkSolver(b *testing.B, solve bn.Solver, maxN int, cost func(n, m float64) float64) {
+	for _, d := range distributions {
+		for n := 100; n <= maxN; n *= 10 {
+			input := d.Generate(rand.New(rand.NewSource(1)), n)
+			for _, m := range []int{1, 8, 64} {
+				if cost(float64(n), float64(m)) > 1e9 {
+					continue
+				}
+				b.Run(fmt.Sprintf("%s/N=%d/M=%d", d.Name, n, m), func(b *testing.B) {
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						solve(input, m)
+					}
+				})
+			}
+		}
+	}
+}
+
+// The snoc solver precomputes O(N^2) least networks, so N is limited by
+// memory.
+func BenchmarkSnoc(b *testing.B) {
+	benchmarkSolver(b, snoc.Solve[bn.Address], 1000, func(n, m float64) float64 {
+		return n * n * m
+	})
+}
+
+// Without the precomputed least networks, memory is O(N * M).
+func BenchmarkSnocLinear(b *testing.B) {
+	benchmarkSolver(b, snoc.SolveLinear[bn.Address], 10000, func(n, m float64) float64 {
+		return n * n * m
+	})
+}
+
+// Only O(address bits) split points per cell.
+func BenchmarkSnocMonotone(b *testing.B) {
+	benchmarkSolver(b, snoc.SolveMonotone[bn.Address], 1000000, func(n, m float64) float64 {
+		return n * m * 32
+	})
+}
+
+func BenchmarkBinary(b *testing.B) {
+	benchmarkSolver(b, binary.Solve[bn.Address], 1000000, func(n, m float64) float64 {
+		return n * m * m
+	})
+}
+
+// With a goroutine per CPU.
+func BenchmarkBinaryParallel(b *testing.B) {
+	benchmarkSolver(b, binary.SolveParallel[bn.Address], 1000000, func(n, m float64) float64 {
+		return n * m * m
+	})
+}
+
+// Without a heap object per node.
+func BenchmarkBinaryArena(b *testing.B) {
+	benchmarkSolver(b, binary.SolveArena[bn.Address], 1000000, func(n, m float64) float64 {
+		return n * m * m
+	})
+}
+
+// Inserting and removing one network, against re-solving from scratch.
+func BenchmarkIncremental(b *testing.B) {
+	for _, d := range distributions {
+		for _, n := range []int{10000, 100000} {
+			input := d.Generate(rand.New(rand.NewSource(1)), n)
+			for _, m := range []int{8, 64} {
+				b.Run(fmt.Sprintf("%s/N=%d/M=%d", d.Name, n, m), func(b *testing.B) {
+					solver := binary.IncrementalSolver{}
+					if err := solver.Init(input, m); err != nil {
+						b.Fatal(err)
+					}
+					r := rand.New(rand.NewSource(2))
+					b.ReportAllocs()
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						network := d.Generate(r, 1)[0]
+						solver.Insert(network)
+						solver.Remove(network)
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package binary
+
+import (
+	"context"
+	bn "github.com/fhltang/boundednet"
+)
+
+// A node of the tree of an ArenaSolverOf.
+type ArenaNodeOf[A bn.Addr[A]] struct {
+	// Indices of the child nodes, or -1 if there are none.
+	Left, Right int32
+
+	// Minimal solution for M=1
+	Network bn.NetworkOf[A]
+
+	// The MinSize and LeftSolution of the node, as in NodeOf, are
+	// the slices [Offset, Offset+Len) of those of the solver.
+	Offset, Len int32
+}
+
+type ArenaNode = ArenaNodeOf[bn.Address]
+
+// A variant of SolverOf whose tree is stored in slices rather than as
+// one heap object per node, and which builds, computes and backtracks
+// the tree iteratively rather than recursively.  Children are only built
+// for nodes which may be split, ie those with Len > 1.
+//
+// Returns the same solutions as SolverOf.
+type ArenaSolverOf[A bn.Addr[A]] struct {
+	// Inputs.
+	Input []bn.NetworkOf[A]
+	M     int
+
+	// Intervals which output networks must not intersect.
+	// Canonicalised by Init.
+	Forbidden []bn.IntervalOf[A]
+
+	// Cost which is minimised.  Defaults to bn.SizeCost if nil.
+	Cost bn.CostOf[A]
+
+	// Limits on the prefix length of output networks, as in SolverOf.
+	MinPrefixLength, MaxPrefixLength int
+
+	// Checked for cancellation while computing MinSize.  Nil means
+	// never cancelled.
+	Context context.Context
+
+	// Nodes of the tree in preorder, so that the root is at index 0
+	// and every node precedes its children.
+	Nodes []ArenaNodeOf[A]
+
+	// Storage for the MinSize and LeftSolution of every node.
+	MinSize      []A
+	LeftSolution []int32
+}
+
+type ArenaSolver = ArenaSolverOf[bn.Address]
+type ArenaSolver6 = ArenaSolverOf[bn.Address6]
+
+// Solve a bounded network problem.  Returns nil if there is no feasible
+// solution or the solver's Context is cancelled.
+func (this *ArenaSolverOf[A]) Solve(input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+	if bn.ValidatePrefixLengths(input, this.MinPrefixLength, this.MaxPrefixLength, m) != nil {
+		return nil
+	}
+	this.Init(input, m)
+	if len(this.Input) == 0 {
+		return []bn.NetworkOf[A]{}
+	}
+	this.BuildTree()
+	if err := this.ComputeMinSize(); err != nil {
+		return nil
+	}
+	return this.Backtrack(this.M)
+}
+
+// Solve a bounded network problem after validating it, stopping early if
+// ctx is cancelled.
+func (this *ArenaSolverOf[A]) SolveContext(ctx context.Context, input []bn.NetworkOf[A], m int) ([]bn.NetworkOf[A], error) {
+	if err := bn.ValidateProblem(input, m); err != nil {
+		return nil, err
+	}
+	if err := bn.ValidatePrefixLengths(input, this.MinPrefixLength, this.MaxPrefixLength, m); err != nil {
+		return nil, err
+	}
+	if len(input) == 0 {
+		return []bn.NetworkOf[A]{}, nil
+	}
+
+	this.Context = ctx
+	this.Init(input, m)
+	this.BuildTree()
+	if err := this.ComputeMinSize(); err != nil {
+		return nil, err
+	}
+	solution := this.Backtrack(this.M)
+	if solution == nil {
+		return nil, bn.ErrInfeasible
+	}
+	return solution, nil
+}
+
+func (this *ArenaSolverOf[A]) Init(input []bn.NetworkOf[A], m int) {
+	this.Input = limitInput(input, this.MinPrefixLength, this.MaxPrefixLength)
+	this.M = m
+	initConstraints(&this.Forbidden, &this.Cost)
+}
+
+// Determine if a network avoids the forbidden intervals and is not
+// shorter than MinPrefixLength.
+func (this *ArenaSolverOf[A]) Allowed(network bn.NetworkOf[A]) bool {
+	return allowed(network, this.Forbidden, this.MinPrefixLength)
+}
+
+// Build the tree for a non-empty input.
+func (this *ArenaSolverOf[A]) BuildTree() {
+	type task struct {
+		i, j, depth int
+
+		// Index of the parent node, or -1 for the root, and which
+		// child of it to build.
+		parent int32
+		left   bool
+	}
+
+	// A tree with N leaves has 2N-1 nodes, and nodes below depth M-1
+	// are not built.
+	size := 2*len(this.Input) - 1
+	if this.M < 31 && 1<<this.M-1 < size {
+		size = 1<<this.M - 1
+	}
+	if cap(this.Nodes) < size {
+		this.Nodes = make([]ArenaNodeOf[A], 0, size)
+	}
+	this.Nodes = this.Nodes[:0]
+	offset := 0
+
+	stack := []task{{0, len(this.Input), 0, -1, false}}
+	for len(stack) > 0 {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		index := int32(len(this.Nodes))
+		if t.parent >= 0 && t.left {
+			this.Nodes[t.parent].Left = index
+		} else if t.parent >= 0 {
+			this.Nodes[t.parent].Right = index
+		}
+
+		n := max(0, this.M-t.depth)
+		network := bn.LeastNetwork(this.Input, t.i, t.j)
+		this.Nodes = append(this.Nodes, ArenaNodeOf[A]{
+			Left:    -1,
+			Right:   -1,
+			Network: network,
+			Offset:  int32(offset),
+			Len:     int32(n),
+		})
+		offset += n
+
+		if t.j-t.i > 1 && n > 1 {
+			midIdx := split(this.Input, t.i, t.j, network)
+			// Push the right child first so that the left is built
+			// first.
+			stack = append(stack,
+				task{midIdx, t.j, t.depth + 1, index, false},
+				task{t.i, midIdx, t.depth + 1, index, true})
+		}
+	}
+
+	this.MinSize = make([]A, offset)
+	this.LeftSolution = make([]int32, offset)
+}
+
+// Compute MinSize for every node, children first by visiting nodes in
+// reverse preorder.  Returns the error of the solver's Context if it is
+// cancelled, leaving MinSize incomplete.
+func (this *ArenaSolverOf[A]) ComputeMinSize() error {
+	for x := len(this.Nodes) - 1; x >= 0; x-- {
+		if this.Context != nil {
+			if err := this.Context.Err(); err != nil {
+				return err
+			}
+		}
+
+		node := &this.Nodes[x]
+		minSize := this.MinSize[node.Offset : node.Offset+node.Len]
+		leftSolution := this.LeftSolution[node.Offset : node.Offset+node.Len]
+		minSize[0] = this.Cost(node.Network)
+		leftSolution[0] = 0
+		if !this.Allowed(node.Network) {
+			leftSolution[0] = Infeasible
+		}
+		if len(minSize) < 2 {
+			continue
+		}
+
+		var leftMinSize, rightMinSize []A
+		var leftLeftSolution, rightLeftSolution []int32
+		if node.Left >= 0 && node.Right >= 0 {
+			l, r := &this.Nodes[node.Left], &this.Nodes[node.Right]
+			leftMinSize = this.MinSize[l.Offset : l.Offset+l.Len]
+			leftLeftSolution = this.LeftSolution[l.Offset : l.Offset+l.Len]
+			rightMinSize = this.MinSize[r.Offset : r.Offset+r.Len]
+			rightLeftSolution = this.LeftSolution[r.Offset : r.Offset+r.Len]
+		}
+
+		for m := 1; m < len(minSize); m++ {
+			minSize[m], leftSolution[m] = minSize[m-1], leftSolution[m-1]
+			for i := 1; i <= m && i-1 < len(leftMinSize); i++ {
+				if m-i >= len(rightMinSize) {
+					continue
+				}
+				if leftLeftSolution[i-1] == Infeasible || rightLeftSolution[m-i] == Infeasible {
+					continue
+				}
+				presolutionSize := leftMinSize[i-1].Add(rightMinSize[m-i])
+				if leftSolution[m] == Infeasible || presolutionSize.Less(minSize[m]) {
+					minSize[m], leftSolution[m] = presolutionSize, int32(i)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Recover a minimal solution with at most m networks.  Returns nil if
+// there is no solution avoiding the forbidden intervals.
+func (this *ArenaSolverOf[A]) Backtrack(m int) []bn.NetworkOf[A] {
+	if this.LeftSolution[this.Nodes[0].Offset+int32(m)-1] == Infeasible {
+		return nil
+	}
+
+	type task struct {
+		node int32
+		m    int32
+	}
+	result := make([]bn.NetworkOf[A], 0, m)
+	stack := []task{{0, int32(m)}}
+	for len(stack) > 0 {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node := &this.Nodes[t.node]
+		left := this.LeftSolution[node.Offset+t.m-1]
+		if left == 0 {
+			result = append(result, node.Network)
+			continue
+		}
+		// Push the right subtree first so that the left is emitted
+		// first.
+		stack = append(stack, task{node.Right, t.m - left}, task{node.Left, left})
+	}
+	return result
+}
+
+// Solve a bounded network problem using ArenaSolverOf.
+func SolveArena[A bn.Addr[A]](input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+	solver := ArenaSolverOf[A]{}
+	return solver.Solve(input, m)
+}
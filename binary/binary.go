@@ -3,8 +3,11 @@
 package binary
 
 import (
+	"context"
 	"fmt"
 	bn "github.com/fhltang/boundednet"
+	"net/netip"
+	"runtime"
 	"sort"
 )
 
@@ -15,71 +18,216 @@ func max(x, y int) int {
 	return x
 }
 
-type Node struct {
+type NodeOf[A bn.Addr[A]] struct {
 	// Left and right child nodes.
-	Left, Right *Node
+	Left, Right *NodeOf[A]
 
-	// Size of minimal solution with up to M networks.  Index 0 is
-	// size of minimal solution for M=1.
-	MinSize []int
+	// Cost of minimal solution with up to M networks.  Index 0 is
+	// cost of minimal solution for M=1.  The cost is the footprint
+	// size unless the solver has a Cost.
+	MinSize []A
 
 	// Minimal solution for M=1
-	Network bn.Network
+	Network bn.NetworkOf[A]
 
 	// Used to recover minimal solution.  Minimal solution with at
 	// most M networks is attained by taking a minimal solution
 	// with at most `LeftSolution[M-1]` networks from the left
 	// subnet together with a minimal solution with at most `M -
 	// LeftSolution[M-1]` networks from the right subnet.
+	//
+	// LeftSolution[M-1] is Infeasible if no presolution with at
+	// most M networks avoids the forbidden intervals.
 	LeftSolution []int
 }
 
-func (this *Node) String() string {
+// Value of LeftSolution when there is no feasible solution.
+const Infeasible = -1
+
+// Default value of SolverOf.ParallelThreshold.
+const DefaultParallelThreshold = 4096
+
+type Node = NodeOf[bn.Address]
+
+func (this *NodeOf[A]) String() string {
 	if this == nil {
 		return "NIL"
 	}
 	return fmt.Sprintf("%v", *this)
 }
 
-type Solver struct {
+type SolverOf[A bn.Addr[A]] struct {
 	// Inputs.
-	Input []bn.Network
+	Input []bn.NetworkOf[A]
 	M     int
 
+	// Intervals which output networks must not intersect.
+	// Canonicalised by Init.
+	Forbidden []bn.IntervalOf[A]
+
+	// Cost which is minimised.  Defaults to bn.SizeCost if nil.
+	Cost bn.CostOf[A]
+
+	// Limits on the prefix length of output networks, or 0 for no
+	// limit.  Init widens or splits input networks outside them, and
+	// shorter networks are not allowed.
+	MinPrefixLength, MaxPrefixLength int
+
+	// Checked for cancellation while computing MinSize.  Nil means
+	// never cancelled.
+	Context context.Context
+
+	// Number of goroutines, including the caller, computing MinSize
+	// for separate subtrees.  At most 1 means MinSize is computed
+	// serially.  Cost must then be safe for concurrent use.
+	Workers int
+
+	// Subtrees covering fewer input networks than this are computed by
+	// a single goroutine.  Defaults to DefaultParallelThreshold if 0.
+	ParallelThreshold int
+
 	// Binary tree
-	Tree *Node
+	Tree *NodeOf[A]
 }
 
-func (this *Solver) Solve(input []bn.Network, m int) []bn.Network {
+type Solver = SolverOf[bn.Address]
+type Solver6 = SolverOf[bn.Address6]
+
+// Solve a bounded network problem.  Returns nil if there is no feasible
+// solution or the solver's Context is cancelled.
+func (this *SolverOf[A]) Solve(input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+	if bn.ValidatePrefixLengths(input, this.MinPrefixLength, this.MaxPrefixLength, m) != nil {
+		return nil
+	}
 	this.Init(input, m)
 	this.Tree = this.BuildTree(0, len(this.Input), 0)
-	this.ComputeMinSize(this.Tree)
+	if err := this.ComputeMinSize(this.Tree); err != nil {
+		return nil
+	}
 
 	return this.Backtrack(this.Tree, this.M)
 }
 
-func (this *Solver) Init(input []bn.Network, m int) {
-	this.Input = bn.NormaliseInput(input)
+// Solve a bounded network problem after validating it, stopping early if
+// ctx is cancelled.
+func (this *SolverOf[A]) SolveContext(ctx context.Context, input []bn.NetworkOf[A], m int) ([]bn.NetworkOf[A], error) {
+	if err := bn.ValidateProblem(input, m); err != nil {
+		return nil, err
+	}
+	if err := bn.ValidatePrefixLengths(input, this.MinPrefixLength, this.MaxPrefixLength, m); err != nil {
+		return nil, err
+	}
+	if len(input) == 0 {
+		return []bn.NetworkOf[A]{}, nil
+	}
+
+	this.Context = ctx
+	this.Init(input, m)
+	this.Tree = this.BuildTree(0, len(this.Input), 0)
+	if err := this.ComputeMinSize(this.Tree); err != nil {
+		return nil, err
+	}
+	solution := this.Backtrack(this.Tree, this.M)
+	if solution == nil {
+		return nil, bn.ErrInfeasible
+	}
+	return solution, nil
+}
+
+// Solve for the fewest networks whose cost exceeds the cost of the input
+// footprint by at most budget.  The bound M is doubled until MinSize at
+// the root attains the budget.  Returns the solution and its number of
+// networks, which is 0 for empty input.
+func (this *SolverOf[A]) SolveBudget(input []bn.NetworkOf[A], budget A) ([]bn.NetworkOf[A], int, error) {
+	this.Init(input, 1)
+	if len(this.Input) == 0 {
+		return []bn.NetworkOf[A]{}, 0, nil
+	}
+
+	n := len(this.Input)
+	inputCost := bn.FootprintCost(bn.IntervalSlice(this.Input), this.Cost)
+	checked := 0
+	for this.M = 1; checked < n; this.M = min(2*this.M, n) {
+		this.Tree = this.BuildTree(0, n, 0)
+		if err := this.ComputeMinSize(this.Tree); err != nil {
+			return nil, 0, err
+		}
+		for m := checked + 1; m <= this.M; m++ {
+			if this.Tree.LeftSolution[m-1] == Infeasible {
+				continue
+			}
+			if !budget.Less(this.Tree.MinSize[m-1].Sub(inputCost)) {
+				return this.Backtrack(this.Tree, m), m, nil
+			}
+		}
+		checked = this.M
+	}
+	return nil, 0, bn.ErrInfeasible
+}
+
+func (this *SolverOf[A]) Init(input []bn.NetworkOf[A], m int) {
+	this.Input = limitInput(input, this.MinPrefixLength, this.MaxPrefixLength)
 	this.M = m
+	initConstraints(&this.Forbidden, &this.Cost)
+}
+
+// Determine if a network avoids the forbidden intervals and is not
+// shorter than MinPrefixLength.
+func (this *SolverOf[A]) Allowed(network bn.NetworkOf[A]) bool {
+	return allowed(network, this.Forbidden, this.MinPrefixLength)
 }
 
-func (this *Solver) BuildTree(i, j, depth int) *Node {
+// Normalise input networks after applying prefix length limits, where 0
+// means no limit.
+func limitInput[A bn.Addr[A]](input []bn.NetworkOf[A], minLength, maxLength int) []bn.NetworkOf[A] {
+	if minLength != 0 || maxLength != 0 {
+		input = bn.LimitPrefixLengths(input, minLength, maxLength)
+	}
+	return bn.NormaliseInput(input)
+}
+
+// Canonicalise the forbidden intervals and default the cost to
+// bn.SizeCost.
+func initConstraints[A bn.Addr[A]](forbidden *[]bn.IntervalOf[A], cost *bn.CostOf[A]) {
+	*forbidden = bn.Canonical(*forbidden)
+	if *cost == nil {
+		*cost = bn.SizeCost[A]
+	}
+}
+
+// Determine if a network avoids the canonical forbidden intervals and is
+// not shorter than minLength, where 0 means no limit.
+func allowed[A bn.Addr[A]](network bn.NetworkOf[A], forbidden []bn.IntervalOf[A], minLength int) bool {
+	if minLength != 0 && network.PrefixLength() < minLength {
+		return false
+	}
+	return !bn.Intersects(bn.IntervalOf[A](network), forbidden)
+}
+
+// Split the normalised networks input[i:j], whose least network is
+// network, at its midpoint.  Returns the index of the first network in
+// the right half.
+func split[A bn.Addr[A]](input []bn.NetworkOf[A], i, j int, network bn.NetworkOf[A]) int {
+	midAddr := network.Left.Add(network.Right).Rsh(1)
+	return i + sort.Search(j-i, func(k int) bool {
+		return !input[i+k].Left.Less(midAddr)
+	})
+}
+
+func (this *SolverOf[A]) BuildTree(i, j, depth int) *NodeOf[A] {
 	if i == j {
 		return nil
 	}
 
-	node := &Node{
-		MinSize:      make([]int, max(0, this.M-depth)),
+	node := &NodeOf[A]{
+		MinSize:      make([]A, max(0, this.M-depth)),
 		LeftSolution: make([]int, max(0, this.M-depth)),
 	}
 
 	node.Network = bn.LeastNetwork(this.Input, i, j)
 
 	if j-i > 1 {
-		midAddr := (node.Network.Left + node.Network.Right) / 2
-		midIdx := i + sort.Search(j-i, func(k int) bool {
-			return midAddr <= this.Input[i+k].Left
-		})
+		midIdx := split(this.Input, i, j, node.Network)
 		node.Left = this.BuildTree(i, midIdx, depth+1)
 		node.Right = this.BuildTree(midIdx, j, depth+1)
 	}
@@ -87,15 +235,101 @@ func (this *Solver) BuildTree(i, j, depth int) *Node {
 	return node
 }
 
-func (this *Solver) ComputeMinSize(node *Node) {
+// Compute MinSize for the subtree, children first.  Returns the error of
+// the solver's Context if it is cancelled, leaving MinSize incomplete.
+func (this *SolverOf[A]) ComputeMinSize(node *NodeOf[A]) error {
+	if this.Workers > 1 {
+		threshold := this.ParallelThreshold
+		if threshold == 0 {
+			threshold = DefaultParallelThreshold
+		}
+		return this.computeMinSizeParallel(node, threshold, make(chan struct{}, this.Workers-1))
+	}
+	return this.computeMinSize(node)
+}
+
+func (this *SolverOf[A]) computeMinSize(node *NodeOf[A]) error {
+	if this.Context != nil {
+		if err := this.Context.Err(); err != nil {
+			return err
+		}
+	}
+
 	if node.Left != nil && len(node.MinSize) > 1 {
-		this.ComputeMinSize(node.Left)
+		if err := this.computeMinSize(node.Left); err != nil {
+			return err
+		}
 	}
 	if node.Right != nil && len(node.MinSize) > 1 {
-		this.ComputeMinSize(node.Right)
+		if err := this.computeMinSize(node.Right); err != nil {
+			return err
+		}
+	}
+
+	this.combine(node)
+	return nil
+}
+
+// Like computeMinSize, but computes the left subtree in a new goroutine
+// if it covers at least threshold input networks and a worker is free.
+// Otherwise the subtrees are computed by the calling goroutine.  Each
+// node is computed from its children as in the serial solver, so MinSize
+// is the same.
+func (this *SolverOf[A]) computeMinSizeParallel(node *NodeOf[A], threshold int, workers chan struct{}) error {
+	if node.Left == nil || node.Right == nil || len(node.MinSize) <= 1 || this.inputsWithin(node.Network) < threshold {
+		return this.computeMinSize(node)
+	}
+	if this.Context != nil {
+		if err := this.Context.Err(); err != nil {
+			return err
+		}
 	}
 
-	node.MinSize[0] = node.Network.Size()
+	var leftErr, rightErr error
+	select {
+	case workers <- struct{}{}:
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			leftErr = this.computeMinSizeParallel(node.Left, threshold, workers)
+			<-workers
+		}()
+		rightErr = this.computeMinSizeParallel(node.Right, threshold, workers)
+		<-done
+	default:
+		leftErr = this.computeMinSizeParallel(node.Left, threshold, workers)
+		if leftErr == nil {
+			rightErr = this.computeMinSizeParallel(node.Right, threshold, workers)
+		}
+	}
+	if leftErr != nil {
+		return leftErr
+	}
+	if rightErr != nil {
+		return rightErr
+	}
+
+	this.combine(node)
+	return nil
+}
+
+// Number of input networks within a network.
+func (this *SolverOf[A]) inputsWithin(network bn.NetworkOf[A]) int {
+	i := sort.Search(len(this.Input), func(k int) bool {
+		return !this.Input[k].Left.Less(network.Left)
+	})
+	j := sort.Search(len(this.Input), func(k int) bool {
+		return !this.Input[k].Left.Less(network.Right)
+	})
+	return j - i
+}
+
+// Compute MinSize for a node whose children have been computed.
+func (this *SolverOf[A]) combine(node *NodeOf[A]) {
+	node.MinSize[0] = this.Cost(node.Network)
+	if !this.Allowed(node.Network) {
+		node.LeftSolution[0] = Infeasible
+	}
 
 	for m := 1; m < len(node.MinSize); m++ {
 		node.MinSize[m], node.LeftSolution[m] = node.MinSize[m-1], node.LeftSolution[m-1]
@@ -104,8 +338,11 @@ func (this *Solver) ComputeMinSize(node *Node) {
 		}
 		for i := 1; i <= m; i++ {
 			if (i-1) < len(node.Left.MinSize) && (m-i) < len(node.Right.MinSize) {
-				presolutionSize := node.Left.MinSize[i-1] + node.Right.MinSize[m-i]
-				if presolutionSize < node.MinSize[m] {
+				if node.Left.LeftSolution[i-1] == Infeasible || node.Right.LeftSolution[m-i] == Infeasible {
+					continue
+				}
+				presolutionSize := node.Left.MinSize[i-1].Add(node.Right.MinSize[m-i])
+				if node.LeftSolution[m] == Infeasible || presolutionSize.Less(node.MinSize[m]) {
 					node.MinSize[m], node.LeftSolution[m] = presolutionSize, i
 				}
 			}
@@ -113,12 +350,17 @@ func (this *Solver) ComputeMinSize(node *Node) {
 	}
 }
 
-func (this *Solver) Backtrack(node *Node, m int) []bn.Network {
-	result := make([]bn.Network, 0, m)
+// Recover a minimal solution with at most m networks for the subtree.
+// Returns nil if there is no solution avoiding the forbidden intervals.
+func (this *SolverOf[A]) Backtrack(node *NodeOf[A], m int) []bn.NetworkOf[A] {
+	if node.LeftSolution[m-1] == Infeasible {
+		return nil
+	}
+	result := make([]bn.NetworkOf[A], 0, m)
 	return this.backtrack(result, node, m)
 }
 
-func (this *Solver) backtrack(dest []bn.Network, node *Node, m int) []bn.Network {
+func (this *SolverOf[A]) backtrack(dest []bn.NetworkOf[A], node *NodeOf[A], m int) []bn.NetworkOf[A] {
 	if node.LeftSolution[m-1] == 0 {
 		return append(dest, node.Network)
 	}
@@ -127,7 +369,113 @@ func (this *Solver) backtrack(dest []bn.Network, node *Node, m int) []bn.Network
 	return this.backtrack(dest, node.Right, m-node.LeftSolution[m-1])
 }
 
-func Solve(input []bn.Network, m int) []bn.Network {
-	solver := Solver{}
+// Minimal solutions for every bound from 1 to M, read from MinSize at the
+// root.  Bounds with no feasible solution are omitted, and there are no
+// points for empty input.  Must be called after ComputeMinSize.
+func (this *SolverOf[A]) Frontier() []bn.FrontierPointOf[A] {
+	result := make([]bn.FrontierPointOf[A], 0, this.M)
+	if len(this.Input) == 0 {
+		return result
+	}
+	for m := 1; m <= this.M; m++ {
+		if this.Tree.LeftSolution[m-1] == Infeasible {
+			continue
+		}
+		result = append(result, bn.FrontierPointOf[A]{
+			M:        m,
+			MinSize:  this.Tree.MinSize[m-1],
+			Solution: this.Backtrack(this.Tree, m),
+		})
+	}
+	return result
+}
+
+func Solve[A bn.Addr[A]](input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+	solver := SolverOf[A]{}
 	return solver.Solve(input, m)
 }
+
+// Solve a bounded network problem computing MinSize with one goroutine
+// per CPU.
+func SolveParallel[A bn.Addr[A]](input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+	solver := SolverOf[A]{Workers: runtime.GOMAXPROCS(0)}
+	return solver.Solve(input, m)
+}
+
+// Solve a bounded network problem whose output must avoid the forbidden
+// intervals.
+func SolveForbidden[A bn.Addr[A]](input []bn.NetworkOf[A], forbidden []bn.IntervalOf[A], m int) ([]bn.NetworkOf[A], error) {
+	solver := SolverOf[A]{Forbidden: forbidden}
+	solution := solver.Solve(input, m)
+	if solution == nil {
+		return nil, bn.ErrInfeasible
+	}
+	return solution, nil
+}
+
+// Solve a bounded network problem minimising the total cost of the output
+// networks instead of their footprint size.
+func SolveWeighted[A bn.Addr[A]](input []bn.NetworkOf[A], m int, cost bn.CostOf[A]) []bn.NetworkOf[A] {
+	solver := SolverOf[A]{Cost: cost}
+	return solver.Solve(input, m)
+}
+
+// Solve for the fewest networks whose footprint exceeds that of the input
+// by at most budget addresses.
+func SolveBudget[A bn.Addr[A]](input []bn.NetworkOf[A], budget A) ([]bn.NetworkOf[A], int, error) {
+	solver := SolverOf[A]{}
+	return solver.SolveBudget(input, budget)
+}
+
+// Minimal solutions of a bounded network problem for every bound from 1
+// to m, or none if m <= 0.
+func SolveFrontier[A bn.Addr[A]](input []bn.NetworkOf[A], m int) []bn.FrontierPointOf[A] {
+	if m <= 0 {
+		return []bn.FrontierPointOf[A]{}
+	}
+	solver := SolverOf[A]{}
+	solver.Init(input, m)
+	if len(solver.Input) > 0 {
+		solver.Tree = solver.BuildTree(0, len(solver.Input), 0)
+		solver.ComputeMinSize(solver.Tree)
+	}
+	return solver.Frontier()
+}
+
+// Solve a bounded network problem whose output networks have prefix
+// lengths between minLength and maxLength, where 0 means no limit.
+func SolvePrefixLengths[A bn.Addr[A]](input []bn.NetworkOf[A], m, minLength, maxLength int) ([]bn.NetworkOf[A], error) {
+	solver := SolverOf[A]{MinPrefixLength: minLength, MaxPrefixLength: maxLength}
+	return solver.SolveContext(context.Background(), input, m)
+}
+
+// Solve a bounded network problem after validating it, stopping early if
+// ctx is cancelled.
+func SolveContext[A bn.Addr[A]](ctx context.Context, input []bn.NetworkOf[A], m int) ([]bn.NetworkOf[A], error) {
+	solver := SolverOf[A]{}
+	return solver.SolveContext(ctx, input, m)
+}
+
+// Solve a bounded network problem given as IPv4 or IPv6 prefixes.  All
+// prefixes must belong to the same address family.
+func SolvePrefixes(input []netip.Prefix, m int) ([]netip.Prefix, error) {
+	return bn.SolvePrefixes(input, m, Solve[bn.Address], Solve[bn.Address6])
+}
+
+// Solve a bounded network problem whose input is given as arbitrary
+// address ranges.
+func SolveIntervals[A bn.Addr[A]](input []bn.IntervalOf[A], m int) []bn.NetworkOf[A] {
+	return bn.SolveIntervals(input, m, Solve[A])
+}
+
+// Solve a bounded network problem and explain which input networks
+// each network of the solution covers.
+func SolveReport[A bn.Addr[A]](input []bn.NetworkOf[A], m int) bn.ReportOf[A] {
+	return bn.SolveReport(input, m, Solve[A])
+}
+
+// Solve a bounded network problem for each group, with at most m networks
+// in total.
+func SolveGroups[A bn.Addr[A]](groups []bn.GroupOf[A], m int) ([]bn.FrontierPointOf[A], error) {
+	return bn.SolveGroups(groups, m, SolveFrontier[A])
+}
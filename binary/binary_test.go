@@ -1,9 +1,15 @@
 package binary_test
 
 import (
+	"context"
+	"errors"
 	bn "github.com/fhltang/boundednet"
 	"github.com/fhltang/boundednet/binary"
+	"github.com/fhltang/boundednet/brute"
+	"math/rand"
+	"net/netip"
 	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -22,29 +28,29 @@ func TestBuildTree(t *testing.T) {
 			Left: &binary.Node{
 				Left:         nil,
 				Right:        nil,
-				MinSize:      []int{0},
+				MinSize:      []bn.Address{0},
 				Network:      bn.Network{0, 4},
 				LeftSolution: []int{0},
 			},
 			Right: &binary.Node{
 				Left:         nil,
 				Right:        nil,
-				MinSize:      []int{0},
+				MinSize:      []bn.Address{0},
 				Network:      bn.Network{14, 16},
 				LeftSolution: []int{0},
 			},
-			MinSize:      []int{0, 0},
+			MinSize:      []bn.Address{0, 0},
 			Network:      bn.Network{0, 16},
 			LeftSolution: []int{0, 0},
 		},
 		Right: &binary.Node{
 			Left:         nil,
 			Right:        nil,
-			MinSize:      []int{0, 0},
+			MinSize:      []bn.Address{0, 0},
 			Network:      bn.Network{128, 256},
 			LeftSolution: []int{0, 0},
 		},
-		MinSize:      []int{0, 0, 0},
+		MinSize:      []bn.Address{0, 0, 0},
 		Network:      bn.Network{0, 256},
 		LeftSolution: []int{0, 0, 0},
 	}
@@ -67,18 +73,18 @@ func TestBuildTree_LargeM(t *testing.T) {
 		Left: &binary.Node{
 			Left:         nil,
 			Right:        nil,
-			MinSize:      []int{0, 0},
+			MinSize:      []bn.Address{0, 0},
 			Network:      bn.Network{0, 4},
 			LeftSolution: []int{0, 0},
 		},
 		Right: &binary.Node{
 			Left:         nil,
 			Right:        nil,
-			MinSize:      []int{0, 0},
+			MinSize:      []bn.Address{0, 0},
 			Network:      bn.Network{14, 16},
 			LeftSolution: []int{0, 0},
 		},
-		MinSize:      []int{0, 0, 0},
+		MinSize:      []bn.Address{0, 0, 0},
 		Network:      bn.Network{0, 16},
 		LeftSolution: []int{0, 0, 0},
 	}
@@ -104,29 +110,29 @@ func TestComputeMinSize(t *testing.T) {
 			Left: &binary.Node{
 				Left:         nil,
 				Right:        nil,
-				MinSize:      []int{4},
+				MinSize:      []bn.Address{4},
 				Network:      bn.Network{0, 4},
 				LeftSolution: []int{0},
 			},
 			Right: &binary.Node{
 				Left:         nil,
 				Right:        nil,
-				MinSize:      []int{2},
+				MinSize:      []bn.Address{2},
 				Network:      bn.Network{14, 16},
 				LeftSolution: []int{0},
 			},
-			MinSize:      []int{16, 6},
+			MinSize:      []bn.Address{16, 6},
 			Network:      bn.Network{0, 16},
 			LeftSolution: []int{0, 1},
 		},
 		Right: &binary.Node{
 			Left:         nil,
 			Right:        nil,
-			MinSize:      []int{128, 128},
+			MinSize:      []bn.Address{128, 128},
 			Network:      bn.Network{128, 256},
 			LeftSolution: []int{0, 0},
 		},
-		MinSize:      []int{256, 144, 134},
+		MinSize:      []bn.Address{256, 144, 134},
 		Network:      bn.Network{0, 256},
 		LeftSolution: []int{0, 1, 2},
 	}
@@ -150,18 +156,18 @@ func TestComputeMinSize_LargeM(t *testing.T) {
 		Left: &binary.Node{
 			Left:         nil,
 			Right:        nil,
-			MinSize:      []int{4, 4},
+			MinSize:      []bn.Address{4, 4},
 			Network:      bn.Network{0, 4},
 			LeftSolution: []int{0, 0},
 		},
 		Right: &binary.Node{
 			Left:         nil,
 			Right:        nil,
-			MinSize:      []int{2, 2},
+			MinSize:      []bn.Address{2, 2},
 			Network:      bn.Network{14, 16},
 			LeftSolution: []int{0, 0},
 		},
-		MinSize:      []int{16, 6, 6},
+		MinSize:      []bn.Address{16, 6, 6},
 		Network:      bn.Network{0, 16},
 		LeftSolution: []int{0, 1, 1},
 	}
@@ -229,3 +235,542 @@ func TestBacktrack_LargeM(t *testing.T) {
 		}
 	}
 }
+
+func TestSolvePrefixes(t *testing.T) {
+	input := []netip.Prefix{
+		netip.MustParsePrefix("2001:db8::/48"),
+		netip.MustParsePrefix("2001:db8:1::/48"),
+		netip.MustParsePrefix("2001:db8:3::/48"),
+	}
+	expected := []netip.Prefix{
+		netip.MustParsePrefix("2001:db8::/47"),
+		netip.MustParsePrefix("2001:db8:3::/48"),
+	}
+	solution, err := binary.SolvePrefixes(input, 2)
+	if err != nil || !reflect.DeepEqual(expected, solution) {
+		t.Error("Expected", expected, "got", solution, err)
+	}
+
+	mixed := append(input, netip.MustParsePrefix("10.0.0.0/8"))
+	if _, err := binary.SolvePrefixes(mixed, 2); !errors.Is(err, bn.ErrFamily) {
+		t.Error("Expected ErrFamily, got", err)
+	}
+}
+
+func TestSolveBudget_Forbidden(t *testing.T) {
+	input := []bn.Network{
+		{0, 1},
+		{1, 2},
+		{32, 36},
+		{60, 64},
+	}
+
+	solver := binary.Solver{Forbidden: []bn.Interval{{40, 41}}}
+	solution, m, err := solver.SolveBudget(input, 1000)
+	expected := []bn.Network{{0, 2}, {32, 36}, {60, 64}}
+	if err != nil || m != 3 || !reflect.DeepEqual(expected, solution) {
+		t.Error("Expected", expected, "got", solution, m, err)
+	}
+
+	solver = binary.Solver{Forbidden: []bn.Interval{{33, 34}}}
+	if _, _, err := solver.SolveBudget(input, 1000); err != bn.ErrInfeasible {
+		t.Error("Expected ErrInfeasible, got", err)
+	}
+}
+
+func TestArenaSolver_BuildTree(t *testing.T) {
+	input := []bn.Network{
+		{0, 1},
+		{1, 2},
+		{32, 36},
+		{60, 64},
+	}
+	solver := binary.ArenaSolver{}
+	solver.Init(input, 2)
+	solver.BuildTree()
+
+	// Nodes at depth 1 may not be split, so their children are not
+	// built.
+	expected := []binary.ArenaNode{
+		{Left: 1, Right: 2, Network: bn.Network{0, 64}, Offset: 0, Len: 2},
+		{Left: -1, Right: -1, Network: bn.Network{0, 2}, Offset: 2, Len: 1},
+		{Left: -1, Right: -1, Network: bn.Network{32, 64}, Offset: 3, Len: 1},
+	}
+	if !reflect.DeepEqual(expected, solver.Nodes) {
+		t.Error("Expected", expected, "got", solver.Nodes)
+	}
+}
+
+// n random aligned networks of up to 2^(maxK-1) addresses within [0, size).
+func randomNetworks(r *rand.Rand, n int, size bn.Address, maxK int) []bn.Network {
+	networks := make([]bn.Network, n)
+	for i := range networks {
+		k := uint(r.Intn(maxK))
+		a := bn.Address(r.Intn(int(size))) >> k << k
+		networks[i] = bn.Network{a, a + 1<<k}
+	}
+	return networks
+}
+
+// A random forbidden address within [0, size), or none.
+func randomForbidden(r *rand.Rand, size bn.Address) []bn.Interval {
+	if r.Intn(2) != 0 {
+		return nil
+	}
+	a := bn.Address(r.Intn(int(size)))
+	return []bn.Interval{{a, a + 1}}
+}
+
+func TestArenaSolver(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 500; trial++ {
+		input := randomNetworks(r, 1+r.Intn(60), 1<<12, 8)
+		forbidden := randomForbidden(r, 1<<12)
+		m := 1 + r.Intn(10)
+
+		reference := binary.Solver{Forbidden: forbidden}
+		expected := reference.Solve(input, m)
+		arena := binary.ArenaSolver{Forbidden: forbidden}
+		solution := arena.Solve(input, m)
+		if !reflect.DeepEqual(expected, solution) {
+			t.Fatal("Input", input, "forbidden", forbidden, "M =", m, "expected", expected, "got", solution)
+		}
+		root := arena.MinSize[arena.Nodes[0].Offset : arena.Nodes[0].Offset+arena.Nodes[0].Len]
+		for i := range root {
+			if reference.Tree.LeftSolution[i] != binary.Infeasible && reference.Tree.MinSize[i] != root[i] {
+				t.Fatal("Input", input, "forbidden", forbidden, "M =", i+1,
+					"expected MinSize", reference.Tree.MinSize[i], "got", root[i])
+			}
+		}
+	}
+}
+
+func TestComputeMinSize_Parallel(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		input := randomNetworks(r, 1+r.Intn(300), 1<<16, 8)
+		forbidden := randomForbidden(r, 1<<16)
+		m := 1 + r.Intn(16)
+
+		serial := binary.Solver{Forbidden: forbidden}
+		expected := serial.Solve(input, m)
+		parallel := binary.Solver{Forbidden: forbidden, Workers: 4, ParallelThreshold: 2}
+		solution := parallel.Solve(input, m)
+		if !reflect.DeepEqual(expected, solution) {
+			t.Fatal("Input", input, "forbidden", forbidden, "M =", m, "expected", expected, "got", solution)
+		}
+		if !reflect.DeepEqual(serial.Tree, parallel.Tree) {
+			t.Fatal("Input", input, "forbidden", forbidden, "M =", m, "trees differ")
+		}
+	}
+}
+
+func TestComputeMinSize_ParallelCancelled(t *testing.T) {
+	input := make([]bn.Network, 1000)
+	for i := range input {
+		input[i] = bn.Network{bn.Address(4 * i), bn.Address(4*i + 1)}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	solver := binary.Solver{Workers: 4, ParallelThreshold: 2}
+	if _, err := solver.SolveContext(ctx, input, 8); !errors.Is(err, context.Canceled) {
+		t.Error("Expected context.Canceled, got", err)
+	}
+}
+
+func TestIncrementalSolver(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 50; trial++ {
+		m := 1 + r.Intn(8)
+		forbidden := randomForbidden(r, 1<<10)
+
+		solver := binary.IncrementalSolver{Forbidden: forbidden}
+		if err := solver.Init([]bn.Network{{0, 1}}, m); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		inputs := map[bn.Network]bool{{0, 1}: true}
+		for op := 0; op < 100; op++ {
+			network := randomNetworks(r, 1, 1<<10, 6)[0]
+
+			var solution []bn.Network
+			var err error
+			if r.Intn(3) == 0 && len(inputs) > 0 {
+				// Remove an existing input.
+				existing := []bn.Network{}
+				for n := range inputs {
+					existing = append(existing, n)
+				}
+				sort.Sort(bn.ByLeftWidth[bn.Address](existing))
+				network = existing[r.Intn(len(existing))]
+				delete(inputs, network)
+				solution, err = solver.Remove(network)
+			} else {
+				inputs[network] = true
+				solution, err = solver.Insert(network)
+			}
+
+			input := []bn.Network{}
+			for n := range inputs {
+				input = append(input, n)
+			}
+			reference := binary.Solver{Forbidden: forbidden}
+			expected := []bn.Network{}
+			if len(input) > 0 {
+				expected = reference.Solve(input, m)
+			}
+			if expected == nil {
+				if err != bn.ErrInfeasible {
+					t.Fatal("Input", input, "forbidden", forbidden, "M =", m, "expected ErrInfeasible, got", solution, err)
+				}
+				continue
+			}
+			if err != nil || !reflect.DeepEqual(expected, solution) {
+				t.Fatal("Input", input, "forbidden", forbidden, "M =", m, "expected", expected, "got", solution, err)
+			}
+			if len(input) > 0 && reference.Tree.MinSize[m-1] != solver.MinSize() {
+				t.Fatal("Input", input, "M =", m, "expected MinSize", reference.Tree.MinSize[m-1], "got", solver.MinSize())
+			}
+		}
+	}
+}
+
+func TestIncrementalSolver_Errors(t *testing.T) {
+	solver := binary.IncrementalSolver{}
+	if err := solver.Init([]bn.Network{{0, 1}}, 0); !errors.Is(err, bn.ErrInvalidBound) {
+		t.Error("Expected ErrInvalidBound, got", err)
+	}
+	if err := solver.Init([]bn.Network{}, 2); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if _, err := solver.Insert(bn.Network{1, 3}); !errors.Is(err, bn.ErrInvalidNetwork) {
+		t.Error("Expected ErrInvalidNetwork, got", err)
+	}
+	solution, err := solver.Remove(bn.Network{0, 1})
+	if err != nil || len(solution) != 0 {
+		t.Error("Expected empty solution, got", solution, err)
+	}
+}
+
+func TestSolveStable(t *testing.T) {
+	type Case struct {
+		Name      string
+		Input     []bn.Network
+		Previous  []bn.Network
+		M         int
+		Tolerance bn.Address
+		Expected  []bn.Network
+		Churn     bn.Churn
+	}
+	input := []bn.Network{
+		bn.ParseNetwork("10.0.0.0/24"),
+		bn.ParseNetwork("10.0.1.0/24"),
+		bn.ParseNetwork("10.0.3.0/24"),
+	}
+	cases := []Case{
+		{
+			"NoPrevious",
+			input, nil, 2, 0,
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/23"), bn.ParseNetwork("10.0.3.0/24")},
+			bn.Churn{Added: []bn.Network{bn.ParseNetwork("10.0.0.0/23"), bn.ParseNetwork("10.0.3.0/24")}},
+		},
+		{
+			// Both solutions cover no extra addresses, and Solve
+			// returns 10.0.0.0/22.
+			"Tie",
+			[]bn.Network{
+				bn.ParseNetwork("10.0.0.0/24"),
+				bn.ParseNetwork("10.0.1.0/24"),
+				bn.ParseNetwork("10.0.2.0/24"),
+				bn.ParseNetwork("10.0.3.0/24"),
+			},
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/23"), bn.ParseNetwork("10.0.2.0/23")},
+			2, 0,
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/23"), bn.ParseNetwork("10.0.2.0/23")},
+			bn.Churn{},
+		},
+		{
+			"PartlyKept",
+			input,
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/22"), bn.ParseNetwork("10.0.3.0/24")},
+			2, 0,
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/23"), bn.ParseNetwork("10.0.3.0/24")},
+			bn.Churn{
+				Added:   []bn.Network{bn.ParseNetwork("10.0.0.0/23")},
+				Removed: []bn.Network{bn.ParseNetwork("10.0.0.0/22")},
+			},
+		},
+		{
+			"OutsideTolerance",
+			input,
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/22")},
+			2, 255,
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/23"), bn.ParseNetwork("10.0.3.0/24")},
+			bn.Churn{
+				Added:   []bn.Network{bn.ParseNetwork("10.0.0.0/23"), bn.ParseNetwork("10.0.3.0/24")},
+				Removed: []bn.Network{bn.ParseNetwork("10.0.0.0/22")},
+			},
+		},
+		{
+			"WithinTolerance",
+			input,
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/22")},
+			2, 256,
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/22")},
+			bn.Churn{},
+		},
+		{
+			// Keeping 10.0.0.0/23 covers 256 extra addresses.
+			"WiderKept",
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/24"), bn.ParseNetwork("10.0.2.0/24")},
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/23"), bn.ParseNetwork("10.0.2.0/24")},
+			2, 1000,
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/23"), bn.ParseNetwork("10.0.2.0/24")},
+			bn.Churn{},
+		},
+		{
+			"WiderKeptSingle",
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/24")},
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/16")},
+			1, 1 << 16,
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/16")},
+			bn.Churn{},
+		},
+		{
+			"Empty",
+			[]bn.Network{},
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/22")},
+			2, 0,
+			[]bn.Network{},
+			bn.Churn{Removed: []bn.Network{bn.ParseNetwork("10.0.0.0/22")}},
+		},
+	}
+
+	for _, c := range cases {
+		solution, churn, err := binary.SolveStable(c.Input, c.Previous, c.M, c.Tolerance)
+		if err != nil {
+			t.Error(c.Name, "unexpected error", err)
+			continue
+		}
+		if !reflect.DeepEqual(c.Expected, solution) {
+			t.Error(c.Name, "Expected", c.Expected, "got", solution)
+		}
+		if !reflect.DeepEqual(c.Churn, churn) {
+			t.Error(c.Name, "Expected churn", c.Churn, "got", churn)
+		}
+	}
+}
+
+// Compare SolveStable with the most previous networks kept by any
+// solution within tolerance, found by trying every set of disjoint least
+// networks and previous networks containing an input network.
+func TestSolveStable_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for trial := 0; trial < 300; trial++ {
+		input := randomNetworks(r, 1+r.Intn(6), 64, 4)
+		m := 1 + r.Intn(4)
+		tolerance := bn.Address(r.Intn(32))
+		candidates := brute.Solver{}
+		candidates.Init(input, m)
+		previous := []bn.Network{}
+		for _, i := range r.Perm(len(candidates.Candidates))[:min(m, len(candidates.Candidates))] {
+			previous = append(previous, candidates.Candidates[i])
+		}
+		previous = append(previous, randomNetworks(r, r.Intn(2), 64, 4)...)
+		if len(candidates.Candidates) > 0 {
+			// A previous network wider than needed.
+			k := uint(r.Intn(4))
+			wider := candidates.Candidates[r.Intn(len(candidates.Candidates))].ToNonEmptyNetwork()
+			previous = append(previous, bn.NonEmptyNetwork{wider.A >> k, wider.K + k}.ToNetwork())
+		}
+
+		keep := map[bn.Network]bool{}
+		for _, network := range previous {
+			keep[network] = true
+		}
+		networks := append([]bn.Network{}, candidates.Candidates...)
+		for network := range keep {
+			for _, in := range input {
+				if network.Valid() && !in.Left.Less(network.Left) && !network.Right.Less(in.Right) {
+					networks = append(networks, network)
+					break
+				}
+			}
+		}
+		kept := func(solution []bn.Network) int {
+			count := 0
+			for _, network := range solution {
+				if keep[network] {
+					count++
+				}
+			}
+			return count
+		}
+
+		// Cost and kept networks of every solution.
+		type option struct {
+			Size bn.Address
+			Kept int
+		}
+		var options []option
+		var search func(i int, chosen []bn.Network)
+		search = func(i int, chosen []bn.Network) {
+			if bn.Subset(bn.IntervalSlice(input), bn.IntervalSlice(chosen)) {
+				options = append(options, option{bn.FootprintSize(bn.IntervalSlice(chosen)), kept(chosen)})
+			}
+			if len(chosen) == m {
+				return
+			}
+			for ; i < len(networks); i++ {
+				network := networks[i]
+				disjoint := true
+				for _, other := range chosen {
+					if network.Left.Less(other.Right) && other.Left.Less(network.Right) {
+						disjoint = false
+					}
+				}
+				if disjoint {
+					search(i+1, append(chosen, network))
+				}
+			}
+		}
+		search(0, nil)
+		minSize := options[0].Size
+		for _, o := range options {
+			minSize = min(minSize, o.Size)
+		}
+		expected := 0
+		for _, o := range options {
+			if !(minSize + tolerance).Less(o.Size) {
+				expected = max(expected, o.Kept)
+			}
+		}
+
+		solution, churn, err := binary.SolveStable(input, previous, m, tolerance)
+		if err != nil {
+			t.Fatal("Input", input, "M =", m, "unexpected error", err)
+		}
+		size := bn.FootprintSize(bn.IntervalSlice(solution))
+		if len(solution) > m || !bn.Subset(bn.IntervalSlice(input), bn.IntervalSlice(solution)) || (minSize + tolerance).Less(size) {
+			t.Fatal("Input", input, "M =", m, "tolerance", tolerance, "invalid solution", solution)
+		}
+		if kept(solution) != expected {
+			t.Fatal("Input", input, "previous", previous, "M =", m, "tolerance", tolerance,
+				"expected", expected, "kept, got", solution)
+		}
+		if !reflect.DeepEqual(churn, bn.Diff(previous, solution)) {
+			t.Fatal("Expected churn", bn.Diff(previous, solution), "got", churn)
+		}
+
+		if reference := binary.Solve(input, m); !reflect.DeepEqual(reference, mustSolveStable(t, input, m)) {
+			t.Fatal("Input", input, "M =", m, "expected", reference, "without previous networks")
+		}
+	}
+}
+
+func mustSolveStable(t *testing.T, input []bn.Network, m int) []bn.Network {
+	solution, _, err := binary.SolveStable(input, nil, m, 0)
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	return solution
+}
+
+func TestPartialSolver(t *testing.T) {
+	type Case struct {
+		Name     string
+		Penalty  bn.Address
+		M        int
+		Expected []bn.Network
+		Dropped  []bn.Network
+	}
+	input := []bn.Network{
+		bn.ParseNetwork("10.0.0.0/24"),
+		bn.ParseNetwork("10.0.1.0/24"),
+		bn.ParseNetwork("10.0.3.0/24"),
+		bn.ParseNetwork("10.0.200.1/32"),
+	}
+	cases := []Case{
+		{"Covered", 100000, 1, []bn.Network{bn.ParseNetwork("10.0.0.0/16")}, []bn.Network{}},
+		{"Dropped", 1000, 1, []bn.Network{bn.ParseNetwork("10.0.0.0/22")}, []bn.Network{bn.ParseNetwork("10.0.200.1/32")}},
+		{
+			"EnoughNetworks", 100, 3,
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/23"), bn.ParseNetwork("10.0.3.0/24"), bn.ParseNetwork("10.0.200.1/32")},
+			[]bn.Network{},
+		},
+		{"Free", 0, 2, []bn.Network{}, input},
+		{"NoNetworks", 100000, 0, []bn.Network{}, input},
+		{"Negative", 100000, -1, nil, nil},
+	}
+
+	for _, c := range cases {
+		solution, dropped := binary.SolvePartial(input, c.M, func(bn.Network) bn.Address { return c.Penalty })
+		if !reflect.DeepEqual(c.Expected, solution) || !reflect.DeepEqual(c.Dropped, dropped) {
+			t.Error(c.Name, "Expected", c.Expected, "dropping", c.Dropped, "got", solution, "dropping", dropped)
+		}
+	}
+}
+
+// Compare PartialSolver with solving for every set of covered inputs.
+func TestPartialSolver_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 300; trial++ {
+		input := bn.NormaliseInput(randomNetworks(r, 1+r.Intn(6), 256, 4))
+		penalties := map[bn.Network]bn.Address{}
+		for _, network := range input {
+			penalties[network] = bn.Address(r.Intn(64))
+		}
+		penalty := func(network bn.Network) bn.Address { return penalties[network] }
+		forbidden := randomForbidden(r, 256)
+		m := 1 + r.Intn(4)
+
+		// Total cost, counting dropped inputs as if covered exactly.
+		total := func(solution, dropped []bn.Network) bn.Address {
+			size := bn.FootprintSize(bn.IntervalSlice(solution))
+			for _, network := range dropped {
+				size += network.Size() + penalty(network)
+			}
+			return size
+		}
+		var expected bn.Address
+		for subset := 0; subset < 1<<len(input); subset++ {
+			covered, dropped := []bn.Network{}, []bn.Network{}
+			for i, network := range input {
+				if subset&(1<<i) != 0 {
+					covered = append(covered, network)
+				} else {
+					dropped = append(dropped, network)
+				}
+			}
+			solution := []bn.Network{}
+			if len(covered) > 0 {
+				var err error
+				if solution, err = binary.SolveForbidden(covered, forbidden, m); err != nil {
+					continue
+				}
+			}
+			if size := total(solution, dropped); subset == 0 || size < expected {
+				expected = size
+			}
+		}
+
+		solver := binary.PartialSolver{Forbidden: forbidden, Penalty: penalty}
+		solution, dropped := solver.Solve(input, m)
+		if len(solution) > m {
+			t.Fatal("Input", input, "M =", m, "got", len(solution), "networks")
+		}
+		for _, network := range solution {
+			if bn.Intersects(bn.IntervalOf[bn.Address](network), bn.Canonical(forbidden)) {
+				t.Fatal("Input", input, "forbidden", forbidden, "got", solution)
+			}
+		}
+		all := append(bn.IntervalSlice(solution), bn.IntervalSlice(dropped)...)
+		if !bn.Subset(bn.IntervalSlice(input), all) {
+			t.Fatal("Input", input, "got", solution, "dropping", dropped, "which does not cover the input")
+		}
+		if size := total(solution, dropped); size != expected || solver.Tree.MinSize[len(solver.Tree.MinSize)-1] != expected {
+			t.Fatal("Input", input, "penalties", penalties, "forbidden", forbidden, "M =", m,
+				"expected cost", expected, "got", size, "for", solution, "dropping", dropped)
+		}
+	}
+}
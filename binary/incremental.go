@@ -0,0 +1,296 @@
+package binary
+
+import (
+	bn "github.com/fhltang/boundednet"
+	"sort"
+)
+
+// A node of the tree of an IncrementalSolverOf.
+type IncrementalNodeOf[A bn.Addr[A]] struct {
+	// Left and right child nodes.
+	Left, Right *IncrementalNodeOf[A]
+
+	// Minimal solution for M=1
+	Network bn.NetworkOf[A]
+
+	// Number of leaves of the subtree.
+	Leaves int
+
+	// As in NodeOf, but of length min(M, Leaves) rather than
+	// depending on the depth of the node, so that only the nodes
+	// whose subtree changes need to be recomputed.
+	MinSize      []A
+	LeftSolution []int
+}
+
+type IncrementalNode = IncrementalNodeOf[bn.Address]
+
+// A binary solver which keeps its tree between solves, so that inserting
+// or removing an input network only recomputes the nodes on the path from
+// the root to it: O(B * M^2) for B address bits, rather than O(N * M^2).
+type IncrementalSolverOf[A bn.Addr[A]] struct {
+	M int
+
+	// Intervals which output networks must not intersect.
+	// Canonicalised by Init.
+	Forbidden []bn.IntervalOf[A]
+
+	// Cost which is minimised.  Defaults to bn.SizeCost if nil.
+	Cost bn.CostOf[A]
+
+	// Every input network, sorted by bn.ByLeftWidth.  Networks
+	// contained in another are not leaves of the tree, but are
+	// remembered in case it is removed.
+	Input []bn.NetworkOf[A]
+
+	// Binary tree of the input networks not contained in another.
+	// Nil if there are none.
+	Tree *IncrementalNodeOf[A]
+}
+
+type IncrementalSolver = IncrementalSolverOf[bn.Address]
+type IncrementalSolver6 = IncrementalSolverOf[bn.Address6]
+
+// Build the tree for the input networks.  Forbidden and Cost must be set
+// before calling Init and not changed afterwards.
+func (this *IncrementalSolverOf[A]) Init(input []bn.NetworkOf[A], m int) error {
+	if err := bn.ValidateProblem(input, m); err != nil {
+		return err
+	}
+	this.M = m
+	initConstraints(&this.Forbidden, &this.Cost)
+
+	this.Input = make([]bn.NetworkOf[A], len(input))
+	copy(this.Input, input)
+	sort.Sort(bn.ByLeftWidth[A](this.Input))
+	unique := this.Input[:0]
+	for i, network := range this.Input {
+		if i == 0 || network != this.Input[i-1] {
+			unique = append(unique, network)
+		}
+	}
+	this.Input = unique
+
+	leaves := bn.NormaliseInput(this.Input)
+	this.Tree = this.build(leaves, 0, len(leaves))
+	return nil
+}
+
+func (this *IncrementalSolverOf[A]) build(leaves []bn.NetworkOf[A], i, j int) *IncrementalNodeOf[A] {
+	if i == j {
+		return nil
+	}
+	node := &IncrementalNodeOf[A]{Network: bn.LeastNetwork(leaves, i, j)}
+	if j-i > 1 {
+		midIdx := split(leaves, i, j, node.Network)
+		node.Left = this.build(leaves, i, midIdx)
+		node.Right = this.build(leaves, midIdx, j)
+	}
+	this.update(node)
+	return node
+}
+
+// Add an input network and return the new minimal solution.
+func (this *IncrementalSolverOf[A]) Insert(network bn.NetworkOf[A]) ([]bn.NetworkOf[A], error) {
+	if err := bn.ValidateProblem([]bn.NetworkOf[A]{network}, this.M); err != nil {
+		return nil, err
+	}
+	i := this.search(network)
+	if i < len(this.Input) && this.Input[i] == network {
+		return this.Solution()
+	}
+	this.Input = append(this.Input, bn.NetworkOf[A]{})
+	copy(this.Input[i+1:], this.Input[i:])
+	this.Input[i] = network
+
+	if leaf := this.leafContaining(network); leaf == nil {
+		this.Tree = this.removeWithin(this.Tree, network)
+		this.Tree = this.insert(this.Tree, network)
+	}
+	return this.Solution()
+}
+
+// Remove an input network and return the new minimal solution.  Removing
+// a network which is not an input has no effect.
+func (this *IncrementalSolverOf[A]) Remove(network bn.NetworkOf[A]) ([]bn.NetworkOf[A], error) {
+	i := this.search(network)
+	if i == len(this.Input) || this.Input[i] != network {
+		return this.Solution()
+	}
+	this.Input = append(this.Input[:i], this.Input[i+1:]...)
+
+	if leaf := this.leafContaining(network); leaf != nil && leaf.Network == network {
+		this.Tree = this.removeWithin(this.Tree, network)
+
+		// Inputs within the removed network become leaves unless
+		// they are within another such input.
+		var right bn.NetworkOf[A]
+		for ; i < len(this.Input) && this.Input[i].Left.Less(network.Right); i++ {
+			if this.Input[i].Left.Less(right.Right) {
+				continue
+			}
+			right = this.Input[i]
+			this.Tree = this.insert(this.Tree, this.Input[i])
+		}
+	}
+	return this.Solution()
+}
+
+// A minimal solution for the current input networks.
+func (this *IncrementalSolverOf[A]) Solution() ([]bn.NetworkOf[A], error) {
+	if this.Tree == nil {
+		return []bn.NetworkOf[A]{}, nil
+	}
+	m := len(this.Tree.MinSize)
+	if this.Tree.LeftSolution[m-1] == Infeasible {
+		return nil, bn.ErrInfeasible
+	}
+	return this.backtrack(make([]bn.NetworkOf[A], 0, m), this.Tree, m), nil
+}
+
+// Cost of a minimal solution.  Zero if there are no input networks.
+func (this *IncrementalSolverOf[A]) MinSize() A {
+	var zero A
+	if this.Tree == nil {
+		return zero
+	}
+	return this.Tree.MinSize[len(this.Tree.MinSize)-1]
+}
+
+func (this *IncrementalSolverOf[A]) backtrack(dest []bn.NetworkOf[A], node *IncrementalNodeOf[A], m int) []bn.NetworkOf[A] {
+	left := node.LeftSolution[min(m, len(node.LeftSolution))-1]
+	if left == 0 {
+		return append(dest, node.Network)
+	}
+	dest = this.backtrack(dest, node.Left, left)
+	return this.backtrack(dest, node.Right, m-left)
+}
+
+// Index of network in Input, or where it would be inserted.
+func (this *IncrementalSolverOf[A]) search(network bn.NetworkOf[A]) int {
+	return sort.Search(len(this.Input), func(i int) bool {
+		return !bn.ByLeftWidth[A]([]bn.NetworkOf[A]{this.Input[i], network}).Less(0, 1)
+	})
+}
+
+func contains[A bn.Addr[A]](x, y bn.NetworkOf[A]) bool {
+	return !y.Left.Less(x.Left) && !x.Right.Less(y.Right)
+}
+
+// Determine if a network strictly within an inner node is within the
+// half of it containing the left child.
+func inLeftHalf[A bn.Addr[A]](node *IncrementalNodeOf[A], network bn.NetworkOf[A]) bool {
+	return network.Left.Less(node.Network.Left.Add(node.Network.Right).Rsh(1))
+}
+
+// The leaf containing network, or nil if there is none.
+func (this *IncrementalSolverOf[A]) leafContaining(network bn.NetworkOf[A]) *IncrementalNodeOf[A] {
+	node := this.Tree
+	for node != nil && contains(node.Network, network) {
+		if node.Left == nil {
+			return node
+		}
+		if inLeftHalf(node, network) {
+			node = node.Left
+		} else {
+			node = node.Right
+		}
+	}
+	return nil
+}
+
+// Remove the leaves within network from the subtree, returning the new
+// subtree.
+func (this *IncrementalSolverOf[A]) removeWithin(node *IncrementalNodeOf[A], network bn.NetworkOf[A]) *IncrementalNodeOf[A] {
+	if node == nil || contains(network, node.Network) {
+		return nil
+	}
+	if !contains(node.Network, network) || node.Left == nil {
+		// Disjoint from network.
+		return node
+	}
+
+	if inLeftHalf(node, network) {
+		node.Left = this.removeWithin(node.Left, network)
+	} else {
+		node.Right = this.removeWithin(node.Right, network)
+	}
+	if node.Left == nil {
+		return node.Right
+	}
+	if node.Right == nil {
+		return node.Left
+	}
+	this.update(node)
+	return node
+}
+
+// Insert a leaf for a network disjoint from the leaves of the subtree,
+// returning the new subtree.
+func (this *IncrementalSolverOf[A]) insert(node *IncrementalNodeOf[A], network bn.NetworkOf[A]) *IncrementalNodeOf[A] {
+	leaf := &IncrementalNodeOf[A]{Network: network}
+	if node == nil {
+		this.update(leaf)
+		return leaf
+	}
+
+	if !contains(node.Network, network) {
+		// The least network containing both has them in different
+		// halves, so it becomes their parent.
+		parent := &IncrementalNodeOf[A]{Left: node, Right: leaf}
+		if network.Left.Less(node.Network.Left) {
+			parent.Left, parent.Right = leaf, node
+		}
+		parent.Network = bn.LeastNetwork([]bn.NetworkOf[A]{parent.Left.Network, parent.Right.Network}, 0, 2)
+		this.update(leaf)
+		this.update(parent)
+		return parent
+	}
+
+	// Network is within node, so node is not a leaf, and is within
+	// the half of node containing one child.
+	if inLeftHalf(node, network) {
+		node.Left = this.insert(node.Left, network)
+	} else {
+		node.Right = this.insert(node.Right, network)
+	}
+	this.update(node)
+	return node
+}
+
+// Recompute Leaves, MinSize and LeftSolution of a node from its children.
+func (this *IncrementalSolverOf[A]) update(node *IncrementalNodeOf[A]) {
+	node.Leaves = 1
+	if node.Left != nil {
+		node.Leaves = node.Left.Leaves + node.Right.Leaves
+	}
+	n := min(this.M, node.Leaves)
+	if cap(node.MinSize) < n {
+		node.MinSize = make([]A, n)
+		node.LeftSolution = make([]int, n)
+	}
+	node.MinSize = node.MinSize[:n]
+	node.LeftSolution = node.LeftSolution[:n]
+
+	node.MinSize[0] = this.Cost(node.Network)
+	node.LeftSolution[0] = 0
+	if !allowed(node.Network, this.Forbidden, 0) {
+		node.LeftSolution[0] = Infeasible
+	}
+
+	for m := 1; m < n; m++ {
+		node.MinSize[m], node.LeftSolution[m] = node.MinSize[m-1], node.LeftSolution[m-1]
+		for i := 1; i <= m; i++ {
+			if i-1 >= len(node.Left.MinSize) || m-i >= len(node.Right.MinSize) {
+				continue
+			}
+			if node.Left.LeftSolution[i-1] == Infeasible || node.Right.LeftSolution[m-i] == Infeasible {
+				continue
+			}
+			presolutionSize := node.Left.MinSize[i-1].Add(node.Right.MinSize[m-i])
+			if node.LeftSolution[m] == Infeasible || presolutionSize.Less(node.MinSize[m]) {
+				node.MinSize[m], node.LeftSolution[m] = presolutionSize, i
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package binary
+
+import (
+	"context"
+	bn "github.com/fhltang/boundednet"
+)
+
+// Value of PartialNodeOf.LeftSolution when the node's network is output.
+const Whole = -2
+
+// A node of the tree of a PartialSolverOf.
+type PartialNodeOf[A bn.Addr[A]] struct {
+	// Left and right child nodes.
+	Left, Right *PartialNodeOf[A]
+
+	// Minimal solution for M=1
+	Network bn.NetworkOf[A]
+
+	// The input networks of the subtree are Input[Lo:Hi].
+	Lo, Hi int
+
+	// MinSize[m] is the cost of a minimal solution with at most m
+	// networks, plus the cost and penalty of each input network it
+	// leaves uncovered.  MinSize[0] leaves every input network of the
+	// subtree uncovered.  Of length min(M, Hi-Lo) + 1, since a subtree
+	// may have no networks however deep it is.
+	MinSize []A
+
+	// LeftSolution[m] is Whole if a minimal solution with at most m
+	// networks is Network, and otherwise the number of networks of
+	// the solution from the left subtree, possibly 0.
+	LeftSolution []int
+}
+
+type PartialNode = PartialNodeOf[bn.Address]
+
+// A variant of SolverOf which may leave input networks uncovered, paying
+// a penalty for each.  It minimises the excess of the output networks
+// over the input networks they cover plus the penalties of the others.
+// There is always a solution, if only to leave every input uncovered.
+type PartialSolverOf[A bn.Addr[A]] struct {
+	// Inputs.
+	Input []bn.NetworkOf[A]
+	M     int
+
+	// Intervals which output networks must not intersect.
+	// Canonicalised by Init.
+	Forbidden []bn.IntervalOf[A]
+
+	// Cost which is minimised.  Defaults to bn.SizeCost if nil.
+	Cost bn.CostOf[A]
+
+	// Penalty for leaving a normalised input network uncovered.  Must
+	// be set.
+	Penalty bn.CostOf[A]
+
+	// Checked for cancellation while computing MinSize.  Nil means
+	// never cancelled.
+	Context context.Context
+
+	// Binary tree
+	Tree *PartialNodeOf[A]
+}
+
+type PartialSolver = PartialSolverOf[bn.Address]
+type PartialSolver6 = PartialSolverOf[bn.Address6]
+
+// Solve a partial bounded network problem.  Returns the solution and the
+// normalised input networks it leaves uncovered, or nils if m < 0 or the
+// solver's Context is cancelled.  If m == 0, every input network is left
+// uncovered.
+func (this *PartialSolverOf[A]) Solve(input []bn.NetworkOf[A], m int) ([]bn.NetworkOf[A], []bn.NetworkOf[A]) {
+	if m < 0 {
+		return nil, nil
+	}
+	this.Init(input, m)
+	if len(this.Input) == 0 {
+		return []bn.NetworkOf[A]{}, []bn.NetworkOf[A]{}
+	}
+	this.Tree = this.BuildTree(0, len(this.Input))
+	if err := this.ComputeMinSize(this.Tree); err != nil {
+		return nil, nil
+	}
+	return this.Backtrack(this.Tree, this.M)
+}
+
+// Solve a partial bounded network problem after validating it, stopping
+// early if ctx is cancelled.
+func (this *PartialSolverOf[A]) SolveContext(ctx context.Context, input []bn.NetworkOf[A], m int) ([]bn.NetworkOf[A], []bn.NetworkOf[A], error) {
+	if err := bn.ValidateProblem(input, m); err != nil {
+		return nil, nil, err
+	}
+	this.Context = ctx
+	solution, dropped := this.Solve(input, m)
+	if solution == nil {
+		return nil, nil, ctx.Err()
+	}
+	return solution, dropped, nil
+}
+
+func (this *PartialSolverOf[A]) Init(input []bn.NetworkOf[A], m int) {
+	this.Input = bn.NormaliseInput(input)
+	this.M = m
+	initConstraints(&this.Forbidden, &this.Cost)
+}
+
+// Determine if a network avoids the forbidden intervals.
+func (this *PartialSolverOf[A]) Allowed(network bn.NetworkOf[A]) bool {
+	return allowed(network, this.Forbidden, 0)
+}
+
+func (this *PartialSolverOf[A]) BuildTree(i, j int) *PartialNodeOf[A] {
+	n := min(this.M, j-i) + 1
+	node := &PartialNodeOf[A]{
+		Network:      bn.LeastNetwork(this.Input, i, j),
+		Lo:           i,
+		Hi:           j,
+		MinSize:      make([]A, n),
+		LeftSolution: make([]int, n),
+	}
+
+	if j-i > 1 {
+		midIdx := split(this.Input, i, j, node.Network)
+		node.Left = this.BuildTree(i, midIdx)
+		node.Right = this.BuildTree(midIdx, j)
+	}
+
+	return node
+}
+
+// Compute MinSize for the subtree, children first.  Returns the error of
+// the solver's Context if it is cancelled, leaving MinSize incomplete.
+func (this *PartialSolverOf[A]) ComputeMinSize(node *PartialNodeOf[A]) error {
+	if this.Context != nil {
+		if err := this.Context.Err(); err != nil {
+			return err
+		}
+	}
+
+	if node.Left == nil {
+		// Leave the input network uncovered, paying for it as if it
+		// were covered exactly.
+		node.MinSize[0] = this.Cost(node.Network).Add(this.Penalty(node.Network))
+	} else {
+		if err := this.ComputeMinSize(node.Left); err != nil {
+			return err
+		}
+		if err := this.ComputeMinSize(node.Right); err != nil {
+			return err
+		}
+		node.MinSize[0] = node.Left.MinSize[0].Add(node.Right.MinSize[0])
+	}
+	node.LeftSolution[0] = 0
+
+	for m := 1; m < len(node.MinSize); m++ {
+		node.MinSize[m], node.LeftSolution[m] = node.MinSize[m-1], node.LeftSolution[m-1]
+		if m == 1 && this.Allowed(node.Network) {
+			if size := this.Cost(node.Network); size.Less(node.MinSize[m]) {
+				node.MinSize[m], node.LeftSolution[m] = size, Whole
+			}
+		}
+		if node.Left == nil {
+			continue
+		}
+		for i := max(0, m-len(node.Right.MinSize)+1); i <= m && i < len(node.Left.MinSize); i++ {
+			presolutionSize := node.Left.MinSize[i].Add(node.Right.MinSize[m-i])
+			if presolutionSize.Less(node.MinSize[m]) {
+				node.MinSize[m], node.LeftSolution[m] = presolutionSize, i
+			}
+		}
+	}
+	return nil
+}
+
+// Recover a minimal solution with at most m networks for the subtree, and
+// the input networks it leaves uncovered.
+func (this *PartialSolverOf[A]) Backtrack(node *PartialNodeOf[A], m int) ([]bn.NetworkOf[A], []bn.NetworkOf[A]) {
+	solution, dropped := []bn.NetworkOf[A]{}, []bn.NetworkOf[A]{}
+	this.backtrack(&solution, &dropped, node, m)
+	return solution, dropped
+}
+
+func (this *PartialSolverOf[A]) backtrack(solution, dropped *[]bn.NetworkOf[A], node *PartialNodeOf[A], m int) {
+	m = min(m, len(node.MinSize)-1)
+	switch left := node.LeftSolution[m]; {
+	case left == Whole:
+		*solution = append(*solution, node.Network)
+	case m == 0 || node.Left == nil:
+		*dropped = append(*dropped, this.Input[node.Lo:node.Hi]...)
+	default:
+		this.backtrack(solution, dropped, node.Left, left)
+		this.backtrack(solution, dropped, node.Right, m-left)
+	}
+}
+
+// Solve a bounded network problem which may leave input networks
+// uncovered at a penalty.  Returns the solution and the normalised input
+// networks it leaves uncovered, or nils if m < 0.  If m == 0, every input
+// network is left uncovered.
+func SolvePartial[A bn.Addr[A]](input []bn.NetworkOf[A], m int, penalty bn.CostOf[A]) ([]bn.NetworkOf[A], []bn.NetworkOf[A]) {
+	solver := PartialSolverOf[A]{Penalty: penalty}
+	return solver.Solve(input, m)
+}
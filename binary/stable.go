@@ -0,0 +1,221 @@
+package binary
+
+import (
+	bn "github.com/fhltang/boundednet"
+)
+
+// How a cell of a stableNodeOf is attained.
+type stableChoice struct {
+	// Networks from the left subtree, as in NodeOf.LeftSolution.
+	Left int
+
+	// Previous networks required from the left and right subtrees.  If
+	// Left is 0, LeftShared is 1 when the node's Kept network is output
+	// instead of its Network.
+	LeftShared, RightShared int
+}
+
+// Like NodeOf, but for solutions sharing networks with a previous
+// solution.
+type stableNodeOf[A bn.Addr[A]] struct {
+	Left, Right *stableNodeOf[A]
+	Network     bn.NetworkOf[A]
+
+	// The cheapest previous network which could be output instead of
+	// Network, if HasKept.
+	Kept    bn.NetworkOf[A]
+	HasKept bool
+
+	// Cost[m][s] is the cost of a minimal solution with at most m+1
+	// networks of which at least s are previous networks.  s is at
+	// most the number of previous networks which could be output for
+	// the subtree.
+	Cost   [][]A
+	Choice [][]stableChoice
+}
+
+// Solve a bounded network problem preferring solutions which keep
+// networks of a previous solution.  Among solutions whose cost exceeds
+// the minimum by at most tolerance, returns one with the most networks
+// in previous, and the networks it adds and removes.
+//
+// A previous network may be kept if it is wider than needed, as long as
+// it contains the least network of the inputs it covers and no others.
+//
+// Each node takes O(M^2 * S^2) for S the number of previous networks
+// within it, so nodes with none take as long as in Solve.
+func (this *SolverOf[A]) SolveStable(input, previous []bn.NetworkOf[A], m int, tolerance A) ([]bn.NetworkOf[A], bn.ChurnOf[A], error) {
+	if err := bn.ValidateProblem(input, m); err != nil {
+		return nil, bn.ChurnOf[A]{}, err
+	}
+	if len(input) == 0 {
+		solution := []bn.NetworkOf[A]{}
+		return solution, bn.Diff(previous, solution), nil
+	}
+
+	this.Init(input, m)
+	this.Tree = this.BuildTree(0, len(this.Input), 0)
+	keep := make(map[bn.NetworkOf[A]]bool, len(previous))
+	for _, network := range previous {
+		keep[network] = true
+	}
+	var zero A
+	space := bn.NetworkOf[A]{zero, zero.FromUint64(1).Lsh(zero.AddressBits())}
+	root, err := this.computeStable(this.Tree, space, keep)
+	if err != nil {
+		return nil, bn.ChurnOf[A]{}, err
+	}
+
+	costs, choices := root.Cost[this.M-1], root.Choice[this.M-1]
+	if choices[0].Left == Infeasible {
+		return nil, bn.ChurnOf[A]{}, bn.ErrInfeasible
+	}
+	limit := costs[0].Add(tolerance)
+	s := len(costs) - 1
+	for choices[s].Left == Infeasible || limit.Less(costs[s]) {
+		s--
+	}
+
+	solution := backtrackStable(make([]bn.NetworkOf[A], 0, this.M), root, this.M, s)
+	return solution, bn.Diff(previous, solution), nil
+}
+
+// Compute Cost and Choice for the subtree, children first.  region is the
+// half of the parent's network containing the subtree, which contains no
+// other input networks.
+func (this *SolverOf[A]) computeStable(node *NodeOf[A], region bn.NetworkOf[A], keep map[bn.NetworkOf[A]]bool) (*stableNodeOf[A], error) {
+	if this.Context != nil {
+		if err := this.Context.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &stableNodeOf[A]{Network: node.Network}
+	n := len(node.MinSize)
+
+	// Previous networks which could be output for the subtree.
+	shared := 0
+	result.Kept, result.HasKept = this.keptNetwork(node.Network, region, keep)
+	if result.HasKept {
+		shared = 1
+	}
+	if n > 1 && node.Left != nil {
+		mid := node.Network.Left.Add(node.Network.Right).Rsh(1)
+		var err error
+		if result.Left, err = this.computeStable(node.Left, bn.NetworkOf[A]{node.Network.Left, mid}, keep); err != nil {
+			return nil, err
+		}
+		if result.Right, err = this.computeStable(node.Right, bn.NetworkOf[A]{mid, node.Network.Right}, keep); err != nil {
+			return nil, err
+		}
+		shared = max(shared, len(result.Left.Cost[0])+len(result.Right.Cost[0])-2)
+	}
+	shared = min(shared, n)
+
+	result.Cost = make([][]A, n)
+	result.Choice = make([][]stableChoice, n)
+	for m := range result.Cost {
+		result.Cost[m] = make([]A, shared+1)
+		result.Choice[m] = make([]stableChoice, shared+1)
+	}
+
+	// As in computeMinSize, but a presolution from the left and right
+	// subtrees sharing s1 and s2 networks shares s1 + s2.
+	costs, choices := result.Cost[0], result.Choice[0]
+	for s := range costs {
+		choices[s].Left = Infeasible
+	}
+	if this.Allowed(node.Network) {
+		costs[0], choices[0] = this.Cost(node.Network), stableChoice{}
+	}
+	if result.HasKept {
+		cost := this.Cost(result.Kept)
+		costs[1], choices[1] = cost, stableChoice{0, 1, 0}
+		if choices[0].Left == Infeasible || cost.Less(costs[0]) {
+			costs[0], choices[0] = cost, choices[1]
+		}
+	}
+	for m := 1; m < n; m++ {
+		costs, choices := result.Cost[m], result.Choice[m]
+		copy(costs, result.Cost[m-1])
+		copy(choices, result.Choice[m-1])
+		if result.Left == nil {
+			continue
+		}
+		for i := 1; i <= m; i++ {
+			if i-1 >= len(result.Left.Cost) || m-i >= len(result.Right.Cost) {
+				continue
+			}
+			leftCosts, leftChoices := result.Left.Cost[i-1], result.Left.Choice[i-1]
+			rightCosts, rightChoices := result.Right.Cost[m-i], result.Right.Choice[m-i]
+			for s1 := range leftCosts {
+				if leftChoices[s1].Left == Infeasible {
+					continue
+				}
+				for s2 := range rightCosts {
+					if rightChoices[s2].Left == Infeasible {
+						continue
+					}
+					s := min(s1+s2, shared)
+					presolutionSize := leftCosts[s1].Add(rightCosts[s2])
+					if choices[s].Left == Infeasible || presolutionSize.Less(costs[s]) {
+						costs[s], choices[s] = presolutionSize, stableChoice{i, s1, s2}
+					}
+				}
+			}
+		}
+
+		// A presolution sharing at least s+1 networks shares at least
+		// s.
+		for s := shared - 1; s >= 0; s-- {
+			if choices[s+1].Left == Infeasible {
+				continue
+			}
+			if choices[s].Left == Infeasible || costs[s+1].Less(costs[s]) {
+				costs[s], choices[s] = costs[s+1], choices[s+1]
+			}
+		}
+	}
+	return result, nil
+}
+
+// Find the cheapest previous network containing network and within
+// region.
+func (this *SolverOf[A]) keptNetwork(network, region bn.NetworkOf[A], keep map[bn.NetworkOf[A]]bool) (bn.NetworkOf[A], bool) {
+	var result bn.NetworkOf[A]
+	var cost A
+	found := false
+	if len(keep) == 0 {
+		return result, found
+	}
+	for candidate := network.ToNonEmptyNetwork(); ; candidate.A, candidate.K = candidate.A.Rsh(1), candidate.K+1 {
+		net := candidate.ToNetwork()
+		if keep[net] && this.Allowed(net) {
+			if c := this.Cost(net); !found || c.Less(cost) {
+				result, cost, found = net, c, true
+			}
+		}
+		if net == region {
+			return result, found
+		}
+	}
+}
+
+func backtrackStable[A bn.Addr[A]](dest []bn.NetworkOf[A], node *stableNodeOf[A], m, s int) []bn.NetworkOf[A] {
+	choice := node.Choice[m-1][s]
+	if choice.Left == 0 && choice.LeftShared > 0 {
+		return append(dest, node.Kept)
+	}
+	if choice.Left == 0 {
+		return append(dest, node.Network)
+	}
+	dest = backtrackStable(dest, node.Left, choice.Left, choice.LeftShared)
+	return backtrackStable(dest, node.Right, m-choice.Left, choice.RightShared)
+}
+
+// Solve a bounded network problem preferring networks of a previous
+// solution among those whose cost is within tolerance of the minimum.
+func SolveStable[A bn.Addr[A]](input, previous []bn.NetworkOf[A], m int, tolerance A) ([]bn.NetworkOf[A], bn.ChurnOf[A], error) {
+	solver := SolverOf[A]{}
+	return solver.SolveStable(input, previous, m, tolerance)
+}
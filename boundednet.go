@@ -1,60 +1,138 @@
 package boundednet
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"sort"
 )
 
-type Solver func([]Network, int) []Network
+type SolverOf[A Addr[A]] func([]NetworkOf[A], int) []NetworkOf[A]
 
-type ByLeftWidth []Network
-func (this ByLeftWidth) Len() int      { return len(this) }
-func (this ByLeftWidth) Swap(i, j int) { this[i], this[j] = this[j], this[i] }
-func (this ByLeftWidth) Less(i, j int) bool {
+type Solver = SolverOf[Address]
+type Solver6 = SolverOf[Address6]
+
+// A solver which can be cancelled through its context and which reports
+// invalid input and infeasibility as errors instead of panicking.
+type ContextSolverOf[A Addr[A]] func(ctx context.Context, input []NetworkOf[A], m int) ([]NetworkOf[A], error)
+
+type ContextSolver = ContextSolverOf[Address]
+type ContextSolver6 = ContextSolverOf[Address6]
+
+// Returned for invalid problems.
+var (
+	ErrInvalidBound   = errors.New("bound M must be positive")
+	ErrInvalidNetwork = errors.New("invalid network")
+)
+
+// Check that a bounded network problem is well formed: m is positive and
+// every input network is a valid network within the address space.
+func ValidateProblem[A Addr[A]](input []NetworkOf[A], m int) error {
+	if m <= 0 {
+		return fmt.Errorf("M=%d: %w", m, ErrInvalidBound)
+	}
+	for i, network := range input {
+		if !network.Valid() || network.Left == network.Right {
+			return fmt.Errorf("input %d [%v, %v): %w", i, network.Left, network.Right, ErrInvalidNetwork)
+		}
+		end := network.Left.FromUint64(1).Lsh(network.Left.AddressBits())
+		if end.Less(network.Right) {
+			return fmt.Errorf("input %d [%v, %v) is outside the address space: %w",
+				i, network.Left, network.Right, ErrInvalidNetwork)
+		}
+	}
+	return nil
+}
+
+// Returned when there is no solution satisfying the constraints of a
+// problem.
+var ErrInfeasible = errors.New("no feasible solution")
+
+// A solver whose output networks must not intersect any of the
+// forbidden intervals.  Returns ErrInfeasible if there is no such
+// solution with at most M networks.
+type ForbiddenSolverOf[A Addr[A]] func(input []NetworkOf[A], forbidden []IntervalOf[A], m int) ([]NetworkOf[A], error)
+
+type ForbiddenSolver = ForbiddenSolverOf[Address]
+type ForbiddenSolver6 = ForbiddenSolverOf[Address6]
+
+// A solver which finds the fewest networks covering the input whose
+// footprint exceeds that of the input by at most budget addresses.
+// Returns the solution and its number of networks.
+type BudgetSolverOf[A Addr[A]] func(input []NetworkOf[A], budget A) ([]NetworkOf[A], int, error)
+
+type BudgetSolver = BudgetSolverOf[Address]
+type BudgetSolver6 = BudgetSolverOf[Address6]
+
+// A minimal solution with at most M networks and its cost, which is its
+// footprint size unless the solver has a Cost.  The points for M = 1, 2,
+// ... trace the trade-off between the number of networks and footprint
+// size.
+type FrontierPointOf[A Addr[A]] struct {
+	M        int
+	MinSize  A
+	Solution []NetworkOf[A]
+}
+
+type FrontierPoint = FrontierPointOf[Address]
+type FrontierPoint6 = FrontierPointOf[Address6]
+
+// Format like a struct, but with MinSize in decimal rather than as an
+// address.
+func (this FrontierPointOf[A]) String() string {
+	return fmt.Sprintf("{%d %s %v}", this.M, this.MinSize.Decimal(), this.Solution)
+}
+
+type ByLeftWidth[A Addr[A]] []NetworkOf[A]
+
+func (this ByLeftWidth[A]) Len() int      { return len(this) }
+func (this ByLeftWidth[A]) Swap(i, j int) { this[i], this[j] = this[j], this[i] }
+func (this ByLeftWidth[A]) Less(i, j int) bool {
 	if this[i].Left == this[j].Left {
-		return this[i].Right > this[j].Right
+		return this[j].Right.Less(this[i].Right)
 	}
-	return this[i].Left < this[j].Left
+	return this[i].Left.Less(this[j].Left)
 }
 
-func NormaliseInput(input []Network) []Network {
-	result := make([]Network, len(input))
+func NormaliseInput[A Addr[A]](input []NetworkOf[A]) []NetworkOf[A] {
+	result := make([]NetworkOf[A], len(input))
 	copy(result, input)
 
 	// Sort input.
-	sort.Sort(ByLeftWidth(result))
+	sort.Sort(ByLeftWidth[A](result))
 
-	// Remove overlapping networks.
+	// Remove networks contained in an earlier one.
+	if len(result) == 0 {
+		return result
+	}
 	i := 0
-	for j := 0; j < len(result)-1; j++ {
-		if result[j].Right <= result[j+1].Left {
-			if i < j {
-				result[i+1] = result[j+1]
-			}
+	for j := 1; j < len(result); j++ {
+		if !result[j].Left.Less(result[i].Right) {
 			i++
+			result[i] = result[j]
 		}
 	}
 	result = result[:i+1]
 	return result
 }
 
-func LeastNetwork(networks []Network, i, j int) Network {
+func LeastNetwork[A Addr[A]](networks []NetworkOf[A], i, j int) NetworkOf[A] {
 	if i == j {
-		return EmptyNetwork()
+		return NetworkOf[A]{}
 	}
 
 	left := networks[i].ToNonEmptyNetwork()
 	right := networks[j-1].ToNonEmptyNetwork()
 	for left != right {
 		if right.K < left.K {
-			right = NonEmptyNetwork{A: right.A >> 1, K: right.K + 1}
+			right = NonEmptyNetworkOf[A]{A: right.A.Rsh(1), K: right.K + 1}
 		} else if left.K < right.K {
-			left = NonEmptyNetwork{A: left.A >> 1, K: left.K + 1}
-		} else if left.A < right.A {
-			right = NonEmptyNetwork{A: right.A >> 1, K: right.K + 1}
-		} else if right.A < left.A {
-			left = NonEmptyNetwork{A: left.A >> 1, K: left.K + 1}
+			left = NonEmptyNetworkOf[A]{A: left.A.Rsh(1), K: left.K + 1}
+		} else if left.A.Less(right.A) {
+			right = NonEmptyNetworkOf[A]{A: right.A.Rsh(1), K: right.K + 1}
+		} else if right.A.Less(left.A) {
+			left = NonEmptyNetworkOf[A]{A: left.A.Rsh(1), K: left.K + 1}
 		}
 	}
 	return left.ToNetwork()
 }
-
@@ -1,10 +1,14 @@
 package boundednet_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	bn "github.com/fhltang/boundednet"
 	"github.com/fhltang/boundednet/binary"
+	"github.com/fhltang/boundednet/brute"
 	"github.com/fhltang/boundednet/snoc"
+	"math/rand"
 	"reflect"
 	"testing"
 )
@@ -76,6 +80,24 @@ func TestNormaliseInput(t *testing.T) {
 				bn.Network{60, 64},
 			},
 		},
+		{
+			"SeveralNested",
+			[]bn.Network{
+				bn.Network{32, 40},
+				bn.Network{33, 34},
+				bn.Network{36, 38},
+				bn.Network{40, 41},
+			},
+			[]bn.Network{
+				bn.Network{32, 40},
+				bn.Network{40, 41},
+			},
+		},
+		{
+			"Empty",
+			[]bn.Network{},
+			[]bn.Network{},
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.Name, func(t *testing.T) {
@@ -91,7 +113,7 @@ func TestNormaliseInput(t *testing.T) {
 
 type Problem struct {
 	Addresses     []bn.Interval
-	FootprintSize int
+	FootprintSize bn.Address
 }
 
 func MakeProblem(x []bn.Network) *Problem {
@@ -113,8 +135,11 @@ func TestSolvers(t *testing.T) {
 		Solve bn.Solver
 	}
 	solvers := []Solver{
-		{"snoc", snoc.Solve},
-		{"binary", binary.Solve},
+		{"snoc", snoc.Solve[bn.Address]},
+		{"snoc-linear", snoc.SolveLinear[bn.Address]},
+		{"snoc-monotone", snoc.SolveMonotone[bn.Address]},
+		{"binary", binary.Solve[bn.Address]},
+		{"binary-arena", binary.SolveArena[bn.Address]},
 	}
 
 	type Case struct {
@@ -291,3 +316,601 @@ func TestSolvers(t *testing.T) {
 		}
 	}
 }
+
+// Random networks, mostly small and close together so that they nest and
+// share least networks, placed in a random part of the address space.
+func randomInput(r *rand.Rand, n int) []bn.Network {
+	bases := []bn.Address{0, bn.ParseNetwork("10.0.0.0/8").Left, bn.ParseNetwork("255.255.255.0/24").Left}
+	base := bases[r.Intn(len(bases))]
+	input := make([]bn.Network, n)
+	for i := range input {
+		k := uint(r.Intn(6))
+		a := bn.Address(r.Intn(256 >> k))
+		input[i] = bn.Network{base + a<<k, base + (a+1)<<k}
+	}
+	return input
+}
+
+func TestSolvers_Differential(t *testing.T) {
+	solvers := map[string]bn.Solver{
+		"snoc":          snoc.Solve[bn.Address],
+		"snoc-linear":   snoc.SolveLinear[bn.Address],
+		"snoc-monotone": snoc.SolveMonotone[bn.Address],
+		"binary":        binary.Solve[bn.Address],
+		"binary-arena":  binary.SolveArena[bn.Address],
+		"binary-par":    binary.SolveParallel[bn.Address],
+	}
+
+	r := rand.New(rand.NewSource(1))
+	trials := 3000
+	if testing.Short() {
+		trials = 300
+	}
+	for trial := 0; trial < trials; trial++ {
+		input := randomInput(r, 1+r.Intn(7))
+		m := 1 + r.Intn(4)
+		problem := MakeProblem(input)
+
+		expected := brute.Solve(input, m)
+		if !problem.IsPresolution(m, expected) {
+			t.Fatal("brute:", expected, "is not a presolution of", input, "M =", m)
+		}
+		expectedSize := bn.FootprintSize(bn.IntervalSlice(expected))
+		for name, solve := range solvers {
+			solution := solve(input, m)
+			if !problem.IsPresolution(m, solution) {
+				t.Fatal(name+":", solution, "is not a presolution of", input, "M =", m)
+			}
+			if size := bn.FootprintSize(bn.IntervalSlice(solution)); size != expectedSize {
+				t.Fatal(name+":", "input", input, "M =", m, "expected size", uint64(expectedSize),
+					"got", uint64(size), "from", solution, "brute", expected)
+			}
+		}
+	}
+}
+
+func TestSolvers6(t *testing.T) {
+	type Solver struct {
+		Name  string
+		Solve bn.Solver6
+	}
+	solvers := []Solver{
+		{"snoc", snoc.Solve[bn.Address6]},
+		{"snoc-linear", snoc.SolveLinear[bn.Address6]},
+		{"snoc-monotone", snoc.SolveMonotone[bn.Address6]},
+		{"binary", binary.Solve[bn.Address6]},
+		{"binary-arena", binary.SolveArena[bn.Address6]},
+	}
+
+	input := []bn.Network6{
+		bn.ParseNetwork6("2001:db8::/48"),
+		bn.ParseNetwork6("2001:db8:1::/48"),
+		bn.ParseNetwork6("2001:db8:ff::/48"),
+		bn.ParseNetwork6("fd00::1/128"),
+	}
+	expected := [][]bn.Network6{
+		[]bn.Network6{
+			bn.ParseNetwork6("::/0"),
+		},
+		[]bn.Network6{
+			bn.ParseNetwork6("2001:db8::/40"),
+			bn.ParseNetwork6("fd00::1/128"),
+		},
+		[]bn.Network6{
+			bn.ParseNetwork6("2001:db8::/47"),
+			bn.ParseNetwork6("2001:db8:ff::/48"),
+			bn.ParseNetwork6("fd00::1/128"),
+		},
+	}
+	for _, solver := range solvers {
+		for m := 1; m <= len(expected); m++ {
+			t.Run(fmt.Sprintf("%s M=%d", solver.Name, m), func(t *testing.T) {
+				solution := solver.Solve(input, m)
+				if !reflect.DeepEqual(expected[m-1], solution) {
+					t.Error("Expected", expected[m-1], "got", solution)
+				}
+			})
+		}
+	}
+}
+
+func TestForbiddenSolvers(t *testing.T) {
+	type Solver struct {
+		Name  string
+		Solve bn.ForbiddenSolver
+	}
+	solvers := []Solver{
+		{"snoc", snoc.SolveForbidden[bn.Address]},
+		{"binary", binary.SolveForbidden[bn.Address]},
+	}
+
+	input := []bn.Network{
+		bn.Network{0, 1},
+		bn.Network{1, 2},
+		bn.Network{32, 36},
+		bn.Network{60, 64},
+	}
+	type Case struct {
+		Name      string
+		Forbidden []bn.Interval
+		// Expected solution for M=1, 2, ... where nil means
+		// infeasible.
+		Expected [][]bn.Network
+	}
+	cases := []Case{
+		{
+			"none",
+			[]bn.Interval{},
+			[][]bn.Network{
+				[]bn.Network{bn.Network{0, 64}},
+				[]bn.Network{bn.Network{0, 2}, bn.Network{32, 64}},
+			},
+		},
+		{
+			"gap",
+			[]bn.Interval{{10, 11}},
+			[][]bn.Network{
+				nil,
+				[]bn.Network{bn.Network{0, 2}, bn.Network{32, 64}},
+			},
+		},
+		{
+			"two_gaps",
+			[]bn.Interval{{40, 41}, {10, 11}},
+			[][]bn.Network{
+				nil,
+				nil,
+				[]bn.Network{bn.Network{0, 2}, bn.Network{32, 36}, bn.Network{60, 64}},
+				[]bn.Network{bn.Network{0, 2}, bn.Network{32, 36}, bn.Network{60, 64}},
+			},
+		},
+		{
+			"adjacent",
+			[]bn.Interval{{2, 4}},
+			[][]bn.Network{
+				nil,
+				[]bn.Network{bn.Network{0, 2}, bn.Network{32, 64}},
+			},
+		},
+		{
+			"input",
+			[]bn.Interval{{33, 34}},
+			[][]bn.Network{nil, nil, nil, nil},
+		},
+	}
+	for _, solver := range solvers {
+		for _, tc := range cases {
+			for m := 1; m <= len(tc.Expected); m++ {
+				t.Run(fmt.Sprintf("%s %s M=%d", solver.Name, tc.Name, m), func(t *testing.T) {
+					expected := tc.Expected[m-1]
+					solution, err := solver.Solve(input, tc.Forbidden, m)
+					if expected == nil {
+						if err != bn.ErrInfeasible {
+							t.Error("Expected infeasible, got", solution, err)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatal("Unexpected error", err)
+					}
+					problem := MakeProblem(input)
+					if !problem.IsPresolution(m, solution) {
+						t.Error("Result is not a presolution.")
+					}
+					for _, network := range solution {
+						if bn.Intersects(bn.Interval(network), bn.Canonical(tc.Forbidden)) {
+							t.Error("Network", network, "is forbidden")
+						}
+					}
+					if bn.FootprintSize(bn.IntervalSlice(solution)) != bn.FootprintSize(bn.IntervalSlice(expected)) {
+						t.Error("Expected", expected, "got", solution)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestPrefixLengthSolvers(t *testing.T) {
+	type Solver struct {
+		Name  string
+		Solve func([]bn.Network, int, int, int) ([]bn.Network, error)
+	}
+	solvers := []Solver{
+		{"snoc", snoc.SolvePrefixLengths[bn.Address]},
+		{"binary", binary.SolvePrefixLengths[bn.Address]},
+		{"arena", solveArenaPrefixLengths},
+	}
+
+	hosts := []bn.Network{
+		bn.ParseNetwork("10.0.0.1/32"),
+		bn.ParseNetwork("10.0.0.9/32"),
+		bn.ParseNetwork("10.0.2.7/32"),
+	}
+	type Case struct {
+		Name        string
+		Input       []bn.Network
+		M           int
+		Min, Max    int
+		Expected    []bn.Network
+		ExpectedErr error
+	}
+	cases := []Case{
+		{"none", hosts, 2, 0, 0, []bn.Network{bn.ParseNetwork("10.0.0.0/28"), bn.ParseNetwork("10.0.2.7/32")}, nil},
+		{"widened", hosts, 2, 0, 24, []bn.Network{bn.ParseNetwork("10.0.0.0/24"), bn.ParseNetwork("10.0.2.0/24")}, nil},
+		{"widened_merged", hosts, 1, 0, 24, []bn.Network{bn.ParseNetwork("10.0.0.0/22")}, nil},
+		{
+			"too_short",
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/8"), bn.ParseNetwork("11.0.0.0/8")},
+			1, 8, 0, nil, bn.ErrInfeasible,
+		},
+		{
+			"not_merged",
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/8"), bn.ParseNetwork("11.0.0.0/8")},
+			2, 8, 0, []bn.Network{bn.ParseNetwork("10.0.0.0/8"), bn.ParseNetwork("11.0.0.0/8")}, nil,
+		},
+		{
+			"split",
+			[]bn.Network{bn.ParseNetwork("8.0.0.0/6")},
+			4, 8, 24,
+			[]bn.Network{
+				bn.ParseNetwork("8.0.0.0/8"),
+				bn.ParseNetwork("9.0.0.0/8"),
+				bn.ParseNetwork("10.0.0.0/8"),
+				bn.ParseNetwork("11.0.0.0/8"),
+			},
+			nil,
+		},
+		{"split_infeasible", []bn.Network{bn.ParseNetwork("8.0.0.0/6")}, 3, 8, 24, nil, bn.ErrInfeasible},
+		{"split_huge", []bn.Network{bn.ParseNetwork("0.0.0.0/0")}, 3, 32, 0, nil, bn.ErrInfeasible},
+		{"min_above_max", hosts, 2, 25, 24, nil, bn.ErrPrefixLength},
+		{"max_too_long", hosts, 2, 0, 33, nil, bn.ErrPrefixLength},
+		{"negative_min", hosts, 2, -1, 0, nil, bn.ErrPrefixLength},
+	}
+	for _, solver := range solvers {
+		for _, tc := range cases {
+			t.Run(fmt.Sprintf("%s %s", solver.Name, tc.Name), func(t *testing.T) {
+				solution, err := solver.Solve(tc.Input, tc.M, tc.Min, tc.Max)
+				if !errors.Is(err, tc.ExpectedErr) {
+					t.Fatal("Expected error", tc.ExpectedErr, "got", err)
+				}
+				if !reflect.DeepEqual(tc.Expected, solution) {
+					t.Error("Expected", tc.Expected, "got", solution)
+				}
+			})
+		}
+	}
+}
+
+func solveArenaPrefixLengths(input []bn.Network, m, minLength, maxLength int) ([]bn.Network, error) {
+	solver := binary.ArenaSolver{MinPrefixLength: minLength, MaxPrefixLength: maxLength}
+	return solver.SolveContext(context.Background(), input, m)
+}
+
+// Compare solvers with prefix length limits with trying every set of
+// networks within the limits.
+func TestPrefixLengthSolvers_Random(t *testing.T) {
+	type Solver struct {
+		Name  string
+		Solve func([]bn.Network, int, int, int) ([]bn.Network, error)
+	}
+	solvers := []Solver{
+		{"snoc", snoc.SolvePrefixLengths[bn.Address]},
+		{"binary", binary.SolvePrefixLengths[bn.Address]},
+		{"arena", solveArenaPrefixLengths},
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 300; trial++ {
+		// Inputs are within [0, 64), which is a /26.
+		input := make([]bn.Network, 1+r.Intn(4))
+		for i := range input {
+			k := uint(r.Intn(4))
+			a := bn.Address(r.Intn(64)) >> k << k
+			input[i] = bn.Network{a, a + 1<<k}
+		}
+		m := 1 + r.Intn(3)
+		minLength, maxLength := 0, 0
+		if r.Intn(3) > 0 {
+			minLength = 25 + r.Intn(8)
+		}
+		if r.Intn(3) > 0 {
+			maxLength = max(minLength, 26) + r.Intn(33-max(minLength, 26))
+		}
+
+		// Networks within the limits intersecting the input.  Ones
+		// shorter than /26 are never better than [0, 64).
+		var candidates []bn.Network
+		for length := max(minLength, 26); length <= 32 && (maxLength == 0 || length <= maxLength); length++ {
+			size := bn.Address(1) << uint(32-length)
+			for left := bn.Address(0); left < 64; left += size {
+				network := bn.Network{left, left + size}
+				for _, x := range input {
+					if x.Left < network.Right && network.Left < x.Right {
+						candidates = append(candidates, network)
+						break
+					}
+				}
+			}
+		}
+		var best []bn.Network
+		var search func(i int, chosen []bn.Network)
+		search = func(i int, chosen []bn.Network) {
+			if bn.Subset(bn.IntervalSlice(input), bn.IntervalSlice(chosen)) {
+				if best == nil || bn.FootprintSize(bn.IntervalSlice(chosen)) < bn.FootprintSize(bn.IntervalSlice(best)) {
+					best = append([]bn.Network{}, chosen...)
+				}
+				return
+			}
+			if len(chosen) == m {
+				return
+			}
+			for ; i < len(candidates); i++ {
+				search(i+1, append(chosen, candidates[i]))
+			}
+		}
+		search(0, nil)
+
+		for _, solver := range solvers {
+			solution, err := solver.Solve(input, m, minLength, maxLength)
+			if best == nil {
+				if !errors.Is(err, bn.ErrInfeasible) {
+					t.Fatal(solver.Name, "input", input, "M =", m, "limits", minLength, maxLength, "expected infeasible, got", solution, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatal(solver.Name, "input", input, "M =", m, "limits", minLength, maxLength, "unexpected error", err)
+			}
+			if !MakeProblem(input).IsPresolution(m, solution) {
+				t.Fatal(solver.Name, "input", input, "M =", m, "got", solution, "which is not a presolution")
+			}
+			for _, network := range solution {
+				length := network.PrefixLength()
+				if length < minLength || (maxLength != 0 && length > maxLength) {
+					t.Fatal(solver.Name, "input", input, "limits", minLength, maxLength, "got", network)
+				}
+			}
+			if bn.FootprintSize(bn.IntervalSlice(solution)) != bn.FootprintSize(bn.IntervalSlice(best)) {
+				t.Fatal(solver.Name, "input", input, "M =", m, "limits", minLength, maxLength, "expected", best, "got", solution)
+			}
+		}
+	}
+}
+
+func TestWeightedSolvers(t *testing.T) {
+	type Solver struct {
+		Name  string
+		Solve func([]bn.Network, int, bn.Cost) []bn.Network
+	}
+	solvers := []Solver{
+		{"snoc", snoc.SolveWeighted[bn.Address]},
+		{"binary", binary.SolveWeighted[bn.Address]},
+	}
+
+	input := []bn.Network{
+		bn.Network{0, 1},
+		bn.Network{2, 3},
+		bn.Network{4, 5},
+		bn.Network{6, 7},
+	}
+	// Covering address 1 is expensive.
+	cost, err := bn.WeightedCost(1, []bn.Weight{{bn.Interval{1, 2}, 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedCost := []bn.Address{107, 107, 6, 4}
+
+	for _, solver := range solvers {
+		for m := 1; m <= len(expectedCost); m++ {
+			t.Run(fmt.Sprintf("%s M=%d", solver.Name, m), func(t *testing.T) {
+				solution := solver.Solve(input, m, cost)
+				problem := MakeProblem(input)
+				if !problem.IsPresolution(m, solution) {
+					t.Error("Result is not a presolution.")
+				}
+				c := bn.FootprintCost(bn.IntervalSlice(solution), cost)
+				if c != expectedCost[m-1] {
+					t.Error("Expected cost", uint64(expectedCost[m-1]), "got", uint64(c), solution)
+				}
+			})
+		}
+	}
+}
+
+func TestBudgetSolvers(t *testing.T) {
+	type Solver struct {
+		Name  string
+		Solve bn.BudgetSolver
+	}
+	solvers := []Solver{
+		{"snoc", snoc.SolveBudget[bn.Address]},
+		{"binary", binary.SolveBudget[bn.Address]},
+	}
+
+	input := []bn.Network{
+		bn.ParseNetwork("192.168.0.0/24"),
+		bn.ParseNetwork("192.168.1.0/24"),
+		bn.ParseNetwork("192.168.3.0/24"),
+		bn.ParseNetwork("192.168.4.0/23"),
+		bn.ParseNetwork("192.168.16.0/21"),
+		bn.ParseNetwork("194.0.0.0/8"),
+		bn.ParseNetwork("200.0.0.11/32"),
+		bn.ParseNetwork("200.0.0.1/32"),
+		bn.ParseNetwork("200.0.0.13/32"),
+		bn.ParseNetwork("200.0.0.3/32"),
+		bn.ParseNetwork("200.0.0.5/32"),
+		bn.ParseNetwork("200.0.0.7/32"),
+		bn.ParseNetwork("200.0.0.9/32"),
+	}
+	inputSize := bn.FootprintSize(bn.IntervalSlice(input))
+
+	// Waste of a minimal solution with at most M networks, indexed
+	// by M-1.
+	wastes := []bn.Address{}
+	for m := 1; m <= len(input); m++ {
+		solution := binary.Solve(input, m)
+		wastes = append(wastes, bn.FootprintSize(bn.IntervalSlice(solution))-inputSize)
+	}
+
+	type Case struct {
+		Budget    bn.Address
+		ExpectedM int
+	}
+	cases := []Case{{1 << 33, 1}}
+	for m := 1; m < len(wastes); m++ {
+		if wastes[m] < wastes[m-1] {
+			cases = append(cases, Case{wastes[m-1] - 1, m + 1}, Case{wastes[m], m + 1})
+		}
+	}
+
+	for _, solver := range solvers {
+		for _, tc := range cases {
+			t.Run(fmt.Sprintf("%s W=%d", solver.Name, tc.Budget), func(t *testing.T) {
+				solution, m, err := solver.Solve(input, tc.Budget)
+				if err != nil {
+					t.Fatal("Unexpected error", err)
+				}
+				if m != tc.ExpectedM || len(solution) != m {
+					t.Error("Expected M", tc.ExpectedM, "got", m, solution)
+				}
+				problem := MakeProblem(input)
+				if !problem.IsPresolution(m, solution) {
+					t.Error("Result is not a presolution.")
+				}
+				if waste := bn.FootprintSize(bn.IntervalSlice(solution)) - inputSize; waste > tc.Budget {
+					t.Error("Waste", waste, "exceeds budget")
+				}
+			})
+		}
+
+		t.Run(solver.Name+" Empty", func(t *testing.T) {
+			solution, m, err := solver.Solve([]bn.Network{}, 0)
+			if err != nil || m != 0 || !reflect.DeepEqual(solution, []bn.Network{}) {
+				t.Error("Expected empty solution, got", solution, m, err)
+			}
+		})
+	}
+}
+
+func TestFrontierPointString(t *testing.T) {
+	point := bn.FrontierPoint{1, 1024, []bn.Network{bn.ParseNetwork("10.0.0.0/22")}}
+	expected := "{1 1024 [10.0.0.0/22]}"
+	if s := fmt.Sprint(point); s != expected {
+		t.Error("Expected", expected, "got", s)
+	}
+}
+
+func TestFrontier(t *testing.T) {
+	type Solver struct {
+		Name          string
+		SolveFrontier func([]bn.Network, int) []bn.FrontierPoint
+	}
+	solvers := []Solver{
+		{"snoc", snoc.SolveFrontier[bn.Address]},
+		{"binary", binary.SolveFrontier[bn.Address]},
+	}
+
+	input := []bn.Network{
+		bn.ParseNetwork("192.168.0.0/24"),
+		bn.ParseNetwork("192.168.1.0/24"),
+		bn.ParseNetwork("192.168.3.0/24"),
+		bn.ParseNetwork("192.168.4.0/23"),
+		bn.ParseNetwork("192.168.16.0/21"),
+		bn.ParseNetwork("194.0.0.0/8"),
+		bn.ParseNetwork("200.0.0.11/32"),
+		bn.ParseNetwork("200.0.0.1/32"),
+		bn.ParseNetwork("200.0.0.13/32"),
+	}
+	maxM := 12
+	problem := MakeProblem(input)
+
+	for _, solver := range solvers {
+		t.Run(solver.Name, func(t *testing.T) {
+			frontier := solver.SolveFrontier(input, maxM)
+			if len(frontier) != maxM {
+				t.Fatal("Expected", maxM, "points, got", len(frontier))
+			}
+			for i, point := range frontier {
+				if point.M != i+1 {
+					t.Error("Expected M", i+1, "got", point.M)
+				}
+				if !problem.IsPresolution(point.M, point.Solution) {
+					t.Error("M", point.M, "result is not a presolution")
+				}
+				size := bn.FootprintSize(bn.IntervalSlice(point.Solution))
+				if size != point.MinSize {
+					t.Error("M", point.M, "MinSize", point.MinSize, "but footprint size", size)
+				}
+				reference := binary.Solve(input, point.M)
+				if size != bn.FootprintSize(bn.IntervalSlice(reference)) {
+					t.Error("M", point.M, "solution", point.Solution, "is not minimal")
+				}
+				if i > 0 && frontier[i-1].MinSize < point.MinSize {
+					t.Error("MinSize is not monotonic at M", point.M)
+				}
+			}
+
+			if frontier := solver.SolveFrontier([]bn.Network{}, maxM); frontier == nil || len(frontier) != 0 {
+				t.Error("Expected empty frontier for empty input, got", frontier)
+			}
+			for _, m := range []int{0, -1} {
+				if frontier := solver.SolveFrontier(input, m); frontier == nil || len(frontier) != 0 {
+					t.Error("Expected empty frontier for M", m, "got", frontier)
+				}
+			}
+		})
+	}
+}
+
+func TestContextSolvers(t *testing.T) {
+	type Solver struct {
+		Name  string
+		Solve bn.ContextSolver
+	}
+	solvers := []Solver{
+		{"snoc", snoc.SolveContext[bn.Address]},
+		{"binary", binary.SolveContext[bn.Address]},
+	}
+
+	input := []bn.Network{
+		bn.Network{0, 1},
+		bn.Network{1, 2},
+		bn.Network{32, 36},
+		bn.Network{60, 64},
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	type Case struct {
+		Name        string
+		Ctx         context.Context
+		Input       []bn.Network
+		M           int
+		Expected    []bn.Network
+		ExpectedErr error
+	}
+	cases := []Case{
+		{"ok", context.Background(), input, 2, []bn.Network{{0, 2}, {32, 64}}, nil},
+		{"empty", context.Background(), []bn.Network{}, 2, []bn.Network{}, nil},
+		{"zero_m", context.Background(), input, 0, nil, bn.ErrInvalidBound},
+		{"negative_m", context.Background(), input, -1, nil, bn.ErrInvalidBound},
+		{"not_network", context.Background(), []bn.Network{{8, 11}}, 1, nil, bn.ErrInvalidNetwork},
+		{"empty_network", context.Background(), []bn.Network{{8, 8}}, 1, nil, bn.ErrInvalidNetwork},
+		{"outside", context.Background(), []bn.Network{{1 << 32, 1 << 33}}, 1, nil, bn.ErrInvalidNetwork},
+		{"cancelled", cancelled, input, 2, nil, context.Canceled},
+	}
+	for _, solver := range solvers {
+		for _, tc := range cases {
+			t.Run(fmt.Sprintf("%s %s", solver.Name, tc.Name), func(t *testing.T) {
+				solution, err := solver.Solve(tc.Ctx, tc.Input, tc.M)
+				if !errors.Is(err, tc.ExpectedErr) {
+					t.Fatal("Expected error", tc.ExpectedErr, "got", err)
+				}
+				if !reflect.DeepEqual(tc.Expected, solution) {
+					t.Error("Expected", tc.Expected, "got", solution)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// An exhaustive solution of the bounded network problem, used as a
+// reference when testing the other solvers.  Only practical for small N.
+
+package brute
+
+import (
+	bn "github.com/fhltang/boundednet"
+	"sort"
+)
+
+type SolverOf[A bn.Addr[A]] struct {
+	// Inputs, sorted by ByLeftWidth.
+	Input []bn.NetworkOf[A]
+	M     int
+
+	// Every network which is the least network containing some input
+	// networks.  Each network of a minimal solution is one of these.
+	Candidates []bn.NetworkOf[A]
+
+	// Best solution found so far.
+	best     []bn.NetworkOf[A]
+	bestSize A
+}
+
+type Solver = SolverOf[bn.Address]
+type Solver6 = SolverOf[bn.Address6]
+
+// Solve a bounded network problem by trying every set of at most M
+// candidate networks.
+func (this *SolverOf[A]) Solve(input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+	this.Init(input, m)
+	this.best = nil
+	this.search(0, nil)
+	if this.best == nil {
+		return []bn.NetworkOf[A]{}
+	}
+	sort.Sort(bn.ByLeftWidth[A](this.best))
+	return this.best
+}
+
+func (this *SolverOf[A]) Init(input []bn.NetworkOf[A], m int) {
+	this.Input = make([]bn.NetworkOf[A], 0, len(input))
+	for _, network := range input {
+		if network.Left != network.Right {
+			this.Input = append(this.Input, network)
+		}
+	}
+	sort.Sort(bn.ByLeftWidth[A](this.Input))
+	this.M = m
+
+	seen := make(map[bn.NetworkOf[A]]bool)
+	this.Candidates = nil
+	for j := 1; j <= len(this.Input); j++ {
+		for i := 0; i < j; i++ {
+			network := bn.LeastNetwork(this.Input, i, j)
+			if !seen[network] {
+				seen[network] = true
+				this.Candidates = append(this.Candidates, network)
+			}
+		}
+	}
+}
+
+// Extend chosen by candidates from index i onwards.
+func (this *SolverOf[A]) search(i int, chosen []bn.NetworkOf[A]) {
+	intervals := bn.IntervalSlice(chosen)
+	if bn.Subset(bn.IntervalSlice(this.Input), intervals) {
+		size := bn.FootprintSize(intervals)
+		if this.best == nil || size.Less(this.bestSize) || (size == this.bestSize && len(chosen) < len(this.best)) {
+			this.best = append([]bn.NetworkOf[A]{}, chosen...)
+			this.bestSize = size
+		}
+		return
+	}
+	if len(chosen) == this.M {
+		return
+	}
+	for ; i < len(this.Candidates); i++ {
+		this.search(i+1, append(chosen, this.Candidates[i]))
+	}
+}
+
+func Solve[A bn.Addr[A]](input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+	solver := SolverOf[A]{}
+	return solver.Solve(input, m)
+}
@@ -0,0 +1,46 @@
+package brute_test
+
+import (
+	bn "github.com/fhltang/boundednet"
+	"github.com/fhltang/boundednet/brute"
+	"reflect"
+	"testing"
+)
+
+func TestInit(t *testing.T) {
+	solver := brute.Solver{}
+	solver.Init([]bn.Network{{4, 8}, {0, 1}, {1, 2}, {2, 2}}, 2)
+
+	expectedInput := []bn.Network{{0, 1}, {1, 2}, {4, 8}}
+	if !reflect.DeepEqual(expectedInput, solver.Input) {
+		t.Error("Expected", expectedInput, "got", solver.Input)
+	}
+	expectedCandidates := []bn.Network{{0, 1}, {0, 2}, {1, 2}, {0, 8}, {4, 8}}
+	if !reflect.DeepEqual(expectedCandidates, solver.Candidates) {
+		t.Error("Expected", expectedCandidates, "got", solver.Candidates)
+	}
+}
+
+func TestSolve(t *testing.T) {
+	type Case struct {
+		Name     string
+		Input    []bn.Network
+		M        int
+		Expected []bn.Network
+	}
+	cases := []Case{
+		{"empty", []bn.Network{}, 1, []bn.Network{}},
+		{"one", []bn.Network{{0, 1}, {1, 2}, {4, 8}}, 1, []bn.Network{{0, 8}}},
+		{"two", []bn.Network{{0, 1}, {1, 2}, {4, 8}}, 2, []bn.Network{{0, 2}, {4, 8}}},
+		{"three", []bn.Network{{0, 1}, {1, 2}, {4, 8}}, 3, []bn.Network{{0, 2}, {4, 8}}},
+		{"nested", []bn.Network{{0, 4}, {1, 2}, {8, 9}}, 2, []bn.Network{{0, 4}, {8, 9}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			solution := brute.Solve(tc.Input, tc.M)
+			if !reflect.DeepEqual(tc.Expected, solution) {
+				t.Error("Expected", tc.Expected, "got", solution)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package boundednet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Networks which change between a previous solution and a new one.
+type ChurnOf[A Addr[A]] struct {
+	// Networks of the new solution which are not in the previous one.
+	Added []NetworkOf[A]
+
+	// Networks of the previous solution which are not in the new one.
+	Removed []NetworkOf[A]
+}
+
+type Churn = ChurnOf[Address]
+type Churn6 = ChurnOf[Address6]
+
+// Determine the networks added and removed by replacing previous with
+// solution.  Both lists are sorted by ByLeftWidth.
+func Diff[A Addr[A]](previous, solution []NetworkOf[A]) ChurnOf[A] {
+	result := ChurnOf[A]{}
+	result.Added = difference(solution, previous)
+	result.Removed = difference(previous, solution)
+	return result
+}
+
+// The networks of x which are not in y, sorted and without duplicates.
+func difference[A Addr[A]](x, y []NetworkOf[A]) []NetworkOf[A] {
+	in := make(map[NetworkOf[A]]bool, len(y))
+	for _, network := range y {
+		in[network] = true
+	}
+	var result []NetworkOf[A]
+	for _, network := range x {
+		if !in[network] {
+			in[network] = true
+			result = append(result, network)
+		}
+	}
+	sort.Sort(ByLeftWidth[A](result))
+	return result
+}
+
+// Format one line per added or removed network.
+func (this ChurnOf[A]) String() string {
+	var b strings.Builder
+	for _, network := range this.Added {
+		fmt.Fprintf(&b, "added %v\n", network)
+	}
+	for _, network := range this.Removed {
+		fmt.Fprintf(&b, "removed %v\n", network)
+	}
+	return b.String()
+}
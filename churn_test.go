@@ -0,0 +1,45 @@
+package boundednet_test
+
+import (
+	bn "github.com/fhltang/boundednet"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type Case struct {
+		Name     string
+		Previous []bn.Network
+		Solution []bn.Network
+		Expected bn.Churn
+	}
+	cases := []Case{
+		{"Empty", nil, nil, bn.Churn{}},
+		{
+			"Same",
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/24"), bn.ParseNetwork("10.0.2.0/23")},
+			[]bn.Network{bn.ParseNetwork("10.0.2.0/23"), bn.ParseNetwork("10.0.0.0/24")},
+			bn.Churn{},
+		},
+		{
+			"Changed",
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/22"), bn.ParseNetwork("192.168.0.0/16"), bn.ParseNetwork("10.0.0.0/22")},
+			[]bn.Network{bn.ParseNetwork("10.0.3.0/24"), bn.ParseNetwork("192.168.0.0/16"), bn.ParseNetwork("10.0.0.0/23")},
+			bn.Churn{
+				Added:   []bn.Network{bn.ParseNetwork("10.0.0.0/23"), bn.ParseNetwork("10.0.3.0/24")},
+				Removed: []bn.Network{bn.ParseNetwork("10.0.0.0/22")},
+			},
+		},
+	}
+	for _, c := range cases {
+		actual := bn.Diff(c.Previous, c.Solution)
+		if !reflect.DeepEqual(c.Expected, actual) {
+			t.Error(c.Name, "Expected", c.Expected, "got", actual)
+		}
+	}
+
+	expected := "added 10.0.0.0/23\nadded 10.0.3.0/24\nremoved 10.0.0.0/22\n"
+	if s := cases[2].Expected.String(); s != expected {
+		t.Error("Expected", expected, "got", s)
+	}
+}
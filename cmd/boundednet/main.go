@@ -0,0 +1,270 @@
+// Command boundednet summarises a list of networks into at most M
+// networks while covering as few extra addresses as possible.
+//
+// Usage:
+//
+//	boundednet [-m M] [-solver snoc|binary] [-report]
+//		[-previous file [-tolerance N]] [-penalty N]
+//		[-min-prefix N] [-max-prefix N] [file ...]
+//
+// Networks are read in CIDR notation or as inclusive address ranges
+// such as 10.0.0.5-10.0.0.200, one per line, from the named files or from
+// standard input.  All networks must be IPv4 or all must be IPv6.  The
+// summary is written in the same format followed by a comment giving the
+// number of extra addresses covered.  With -report, the comment instead
+// lists the inputs covered by each output network and its extra
+// addresses.
+//
+// With -previous, the binary solver prefers to keep the networks of a
+// previous summary, read from the named file, among summaries covering at
+// most -tolerance more extra addresses than the minimum.  The networks
+// added and removed are listed in comments.
+//
+// With -penalty, the binary solver may leave input networks uncovered,
+// at a cost of N extra addresses each, and lists them in comments.
+//
+// With -min-prefix or -max-prefix, output networks have prefix lengths
+// within those limits.  Input networks longer than -max-prefix are
+// widened, and those shorter than -min-prefix are split.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	bn "github.com/fhltang/boundednet"
+	"github.com/fhltang/boundednet/binary"
+	"github.com/fhltang/boundednet/snoc"
+	"io"
+	"os"
+	"strings"
+)
+
+type solvers struct {
+	solve  bn.Solver
+	solve6 bn.Solver6
+}
+
+var solverNames = map[string]solvers{
+	"snoc":   {snoc.Solve[bn.Address], snoc.Solve[bn.Address6]},
+	"binary": {binary.Solve[bn.Address], binary.Solve[bn.Address6]},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("boundednet", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	m := flags.Int("m", 1, "maximum number of output networks")
+	solverName := flags.String("solver", "binary", "solver to use: snoc or binary")
+	report := flags.Bool("report", false, "explain which inputs each output network covers")
+	previousFile := flags.String("previous", "", "file of previous summary whose networks to prefer keeping")
+	tolerance := flags.Uint64("tolerance", 0, "extra addresses allowed beyond the minimum to keep previous networks")
+	penalty := flags.Uint64("penalty", 0, "extra addresses worth leaving each input network uncovered")
+	minPrefix := flags.Int("min-prefix", 0, "minimum prefix length of output networks")
+	maxPrefix := flags.Int("max-prefix", 0, "maximum prefix length of output networks, or 0 for none")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	partial := false
+	flags.Visit(func(f *flag.Flag) {
+		partial = partial || f.Name == "penalty"
+	})
+
+	solver, ok := solverNames[*solverName]
+	if !ok {
+		fmt.Fprintf(stderr, "boundednet: unknown solver %q\n", *solverName)
+		return 2
+	}
+	if *m < 1 {
+		fmt.Fprintf(stderr, "boundednet: -m must be at least 1\n")
+		return 2
+	}
+	if *previousFile != "" && *solverName != "binary" {
+		fmt.Fprintf(stderr, "boundednet: -previous requires -solver=binary\n")
+		return 2
+	}
+	if partial && *solverName != "binary" {
+		fmt.Fprintf(stderr, "boundednet: -penalty requires -solver=binary\n")
+		return 2
+	}
+	if partial && *previousFile != "" {
+		fmt.Fprintf(stderr, "boundednet: -penalty cannot be used with -previous\n")
+		return 2
+	}
+	limited := *minPrefix != 0 || *maxPrefix != 0
+	if limited && (partial || *previousFile != "") {
+		fmt.Fprintf(stderr, "boundednet: -min-prefix and -max-prefix cannot be used with -penalty or -previous\n")
+		return 2
+	}
+
+	networks, networks6, err := readInputs(flags.Args(), stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "boundednet: %v\n", err)
+		return 1
+	}
+
+	if limited {
+		if len(networks6) > 0 {
+			err = bn.ValidatePrefixLengths(bn.NormaliseIntervals(networks6), *minPrefix, *maxPrefix, *m)
+		} else {
+			err = bn.ValidatePrefixLengths(bn.NormaliseIntervals(networks), *minPrefix, *maxPrefix, *m)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "boundednet: %v\n", err)
+			return 1
+		}
+		solver = solvers{
+			limitedSolver[bn.Address](*solverName, *minPrefix, *maxPrefix),
+			limitedSolver[bn.Address6](*solverName, *minPrefix, *maxPrefix),
+		}
+	}
+
+	// Previous networks, or nil without -previous.
+	var previous []bn.Network
+	var previous6 []bn.Network6
+	if *previousFile != "" {
+		intervals, intervals6, err := readInputs([]string{*previousFile}, nil)
+		if err == nil && (len(intervals) > 0 && len(networks6) > 0 || len(intervals6) > 0 && len(networks) > 0) {
+			err = fmt.Errorf("cannot mix IPv4 and IPv6 networks")
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "boundednet: %v\n", err)
+			return 1
+		}
+		previous, previous6 = networksOf(intervals), networksOf(intervals6)
+		solver = solvers{stableSolver(previous, *tolerance), stableSolver(previous6, *tolerance)}
+	}
+	if partial {
+		solver = solvers{partialSolver[bn.Address](*penalty), partialSolver[bn.Address6](*penalty)}
+	}
+
+	if len(networks6) > 0 {
+		summarise(stdout, networks6, *m, solver.solve6, *report, previous6)
+	} else {
+		summarise(stdout, networks, *m, solver.solve, *report, previous)
+	}
+	return 0
+}
+
+// Read networks from each file, or stdin if there are none.
+func readInputs(files []string, stdin io.Reader) ([]bn.Interval, []bn.Interval6, error) {
+	var networks []bn.Interval
+	var networks6 []bn.Interval6
+
+	read := func(name string, r io.Reader) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		ns, err := bn.ParseIntervals(bytes.NewReader(data))
+		if err == nil {
+			networks = append(networks, ns...)
+			return nil
+		}
+		ns6, err6 := bn.ParseIntervals6(bytes.NewReader(data))
+		if err6 != nil {
+			// Report whichever error got further.
+			var parseErr, parseErr6 *bn.ParseError
+			if errors.As(err, &parseErr) && errors.As(err6, &parseErr6) && parseErr6.Line > parseErr.Line {
+				err = err6
+			}
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		networks6 = append(networks6, ns6...)
+		return nil
+	}
+
+	if len(files) == 0 {
+		if err := read("<stdin>", stdin); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = read(name, f)
+		f.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(networks) > 0 && len(networks6) > 0 {
+		return nil, nil, fmt.Errorf("cannot mix IPv4 and IPv6 networks")
+	}
+	return networks, networks6, nil
+}
+
+// Networks of a previous summary, converting each line separately.
+func networksOf[A bn.Addr[A]](intervals []bn.IntervalOf[A]) []bn.NetworkOf[A] {
+	result := []bn.NetworkOf[A]{}
+	for _, intvl := range intervals {
+		result = append(result, bn.NormaliseIntervals([]bn.IntervalOf[A]{intvl})...)
+	}
+	return result
+}
+
+// A solver preferring to keep previous networks.
+func stableSolver[A bn.Addr[A]](previous []bn.NetworkOf[A], tolerance uint64) bn.SolverOf[A] {
+	var zero A
+	return func(input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+		solution, _, _ := binary.SolveStable(input, previous, m, zero.FromUint64(tolerance))
+		return solution
+	}
+}
+
+// A solver whose output networks have prefix lengths within limits.
+func limitedSolver[A bn.Addr[A]](name string, minLength, maxLength int) bn.SolverOf[A] {
+	if name == "snoc" {
+		solver := snoc.BacktrackingSolverOf[A]{MinPrefixLength: minLength, MaxPrefixLength: maxLength}
+		return solver.Solve
+	}
+	solver := binary.SolverOf[A]{MinPrefixLength: minLength, MaxPrefixLength: maxLength}
+	return solver.Solve
+}
+
+// A solver which may leave input networks uncovered for a penalty each.
+func partialSolver[A bn.Addr[A]](penalty uint64) bn.SolverOf[A] {
+	var zero A
+	return func(input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+		solution, _ := binary.SolvePartial(input, m, func(bn.NetworkOf[A]) A {
+			return zero.FromUint64(penalty)
+		})
+		return solution
+	}
+}
+
+func summarise[A bn.Addr[A]](w io.Writer, input []bn.IntervalOf[A], m int, solve bn.SolverOf[A], report bool, previous []bn.NetworkOf[A]) {
+	solution := bn.SolveIntervals(input, m, solve)
+	for _, network := range solution {
+		fmt.Fprintln(w, network)
+	}
+	if previous != nil {
+		for _, line := range strings.SplitAfter(bn.Diff(previous, solution).String(), "\n") {
+			if line != "" {
+				fmt.Fprintf(w, "# %s", line)
+			}
+		}
+	}
+
+	explanation := bn.Explain(bn.NormaliseIntervals(input), solution)
+	if report {
+		for _, line := range strings.SplitAfter(explanation.String(), "\n") {
+			if line != "" {
+				fmt.Fprintf(w, "# %s", line)
+			}
+		}
+		return
+	}
+
+	for _, network := range explanation.Uncovered {
+		fmt.Fprintf(w, "# dropped %v\n", network)
+	}
+	fmt.Fprintf(w, "# extra addresses: %s\n", explanation.Excess.Decimal())
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	type Case struct {
+		Name     string
+		Args     []string
+		Stdin    string
+		Expected string
+	}
+	cases := []Case{
+		{
+			"binary",
+			[]string{"-m", "2"},
+			"192.168.0.0/24\n192.168.1.0/24\n192.168.3.0/24\n10.0.0.1/32\n",
+			"10.0.0.1/32\n192.168.0.0/22\n# extra addresses: 256\n",
+		},
+		{
+			"snoc",
+			[]string{"-m", "3", "-solver", "snoc"},
+			"192.168.0.0/24\n192.168.1.0/24\n192.168.3.0/24\n10.0.0.1/32\n",
+			"10.0.0.1/32\n192.168.0.0/23\n192.168.3.0/24\n# extra addresses: 0\n",
+		},
+		{
+			"ipv6",
+			[]string{"-m", "1"},
+			"# comment\n2001:db8::/33\n2001:db8:8000::/34\n",
+			"2001:db8::/32\n# extra addresses: 19807040628566084398385987584\n",
+		},
+		{
+			"ranges",
+			[]string{"-m", "1"},
+			"10.0.0.5-10.0.0.200\n",
+			"10.0.0.0/24\n# extra addresses: 60\n",
+		},
+		{
+			"report",
+			[]string{"-m", "2", "-report"},
+			"192.168.0.0/24\n192.168.1.0/24\n192.168.3.0/24\n10.0.0.1/32\n",
+			"10.0.0.1/32\n192.168.0.0/22\n" +
+				"# 10.0.0.1/32: excess 0, covers 10.0.0.1/32\n" +
+				"# 192.168.0.0/22: excess 256, covers 192.168.0.0/23 192.168.3.0/24\n" +
+				"# total excess 256\n",
+		},
+		{
+			"penalty",
+			[]string{"-m", "1", "-penalty", "1000"},
+			"10.0.0.0/24\n10.0.1.0/24\n10.0.3.0/24\n10.0.200.1/32\n",
+			"10.0.0.0/22\n# dropped 10.0.200.1/32\n# extra addresses: 256\n",
+		},
+		{
+			"penalty report",
+			[]string{"-m", "1", "-penalty", "1000", "-report"},
+			"10.0.0.0/24\n10.0.1.0/24\n10.0.3.0/24\n10.0.200.1/32\n",
+			"10.0.0.0/22\n" +
+				"# 10.0.0.0/22: excess 256, covers 10.0.0.0/23 10.0.3.0/24\n" +
+				"# 10.0.200.1/32: uncovered\n" +
+				"# total excess 256\n",
+		},
+		{
+			"prefix limits",
+			[]string{"-m", "2", "-max-prefix", "24", "-min-prefix", "8"},
+			"10.0.0.1/32\n10.0.0.9/32\n10.0.2.7/32\n",
+			"10.0.0.0/24\n10.0.2.0/24\n# extra addresses: 509\n",
+		},
+		{
+			"prefix limits snoc",
+			[]string{"-m", "2", "-solver", "snoc", "-min-prefix", "8"},
+			"8.0.0.0/7\n",
+			"8.0.0.0/8\n9.0.0.0/8\n# extra addresses: 0\n",
+		},
+		{
+			"empty",
+			[]string{},
+			"",
+			"# extra addresses: 0\n",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.Args, strings.NewReader(tc.Stdin), &stdout, &stderr)
+			if code != 0 {
+				t.Fatal("Exit code", code, stderr.String())
+			}
+			if stdout.String() != tc.Expected {
+				t.Error("Expected", tc.Expected, "got", stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun_Files(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	os.WriteFile(a, []byte("192.168.0.0/24\n"), 0644)
+	os.WriteFile(b, []byte("192.168.1.0/24\n"), 0644)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{a, b}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatal("Exit code", code, stderr.String())
+	}
+	expected := "192.168.0.0/23\n# extra addresses: 0\n"
+	if stdout.String() != expected {
+		t.Error("Expected", expected, "got", stdout.String())
+	}
+}
+
+func TestRun_Previous(t *testing.T) {
+	type Case struct {
+		Name     string
+		Args     []string
+		Previous string
+		Expected string
+	}
+	cases := []Case{
+		{
+			"changed",
+			[]string{"-m", "2"},
+			"192.168.0.0/22\n10.0.0.0/8\n",
+			"192.168.0.0/23\n192.168.3.0/24\n" +
+				"# added 192.168.0.0/23\n# added 192.168.3.0/24\n" +
+				"# removed 10.0.0.0/8\n# removed 192.168.0.0/22\n" +
+				"# extra addresses: 0\n",
+		},
+		{
+			"tolerance",
+			[]string{"-m", "2", "-tolerance", "256"},
+			"192.168.0.0/22\n10.0.0.0/8\n",
+			"192.168.0.0/22\n# removed 10.0.0.0/8\n# extra addresses: 256\n",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			previous := filepath.Join(t.TempDir(), "previous")
+			os.WriteFile(previous, []byte(tc.Previous), 0644)
+
+			var stdout, stderr bytes.Buffer
+			args := append(tc.Args, "-previous", previous)
+			stdin := "192.168.0.0/24\n192.168.1.0/24\n192.168.3.0/24\n"
+			if code := run(args, strings.NewReader(stdin), &stdout, &stderr); code != 0 {
+				t.Fatal("Exit code", code, stderr.String())
+			}
+			if stdout.String() != tc.Expected {
+				t.Error("Expected", tc.Expected, "got", stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	type Case struct {
+		Name         string
+		Args         []string
+		Stdin        string
+		ExpectedCode int
+	}
+	cases := []Case{
+		{"bad solver", []string{"-solver", "foo"}, "", 2},
+		{"bad m", []string{"-m", "0"}, "", 2},
+		{"bad input", []string{}, "10.0.0.0/8\n10.0.0.256/32\n", 1},
+		{"mixed", []string{}, "10.0.0.0/8\n2001:db8::/32\n", 1},
+		{"missing file", []string{"/nonexistent"}, "", 1},
+		{"previous with snoc", []string{"-solver", "snoc", "-previous", "/nonexistent"}, "", 2},
+		{"penalty with snoc", []string{"-solver", "snoc", "-penalty", "1"}, "", 2},
+		{"penalty with previous", []string{"-penalty", "1", "-previous", "/nonexistent"}, "", 2},
+		{"prefix limits with penalty", []string{"-min-prefix", "8", "-penalty", "1"}, "", 2},
+		{"invalid prefix limits", []string{"-min-prefix", "25", "-max-prefix", "24"}, "10.0.0.0/8\n", 1},
+		{"infeasible prefix limits", []string{"-min-prefix", "8"}, "8.0.0.0/7\n", 1},
+		{"missing previous", []string{"-previous", "/nonexistent"}, "10.0.0.0/8\n", 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.Args, strings.NewReader(tc.Stdin), &stdout, &stderr)
+			if code != tc.ExpectedCode {
+				t.Error("Expected exit code", tc.ExpectedCode, "got", code)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package boundednet
+
+import (
+	"fmt"
+	"sort"
+)
+
+// The cost of covering the addresses of a network.
+//
+// Solvers minimise the total cost of the networks in a solution instead
+// of the footprint size.  For the solvers to be correct, a cost must be
+// a measure: the cost of the union of disjoint networks is the sum of
+// their costs, so that covering more addresses never costs less.
+type CostOf[A Addr[A]] func(NetworkOf[A]) A
+
+type Cost = CostOf[Address]
+type Cost6 = CostOf[Address6]
+
+// The default cost, under which solvers minimise footprint size.
+func SizeCost[A Addr[A]](network NetworkOf[A]) A {
+	return network.Size()
+}
+
+// A weight per address for a range of addresses.
+type WeightOf[A Addr[A]] struct {
+	Interval IntervalOf[A]
+	Weight   uint64
+}
+
+type Weight = WeightOf[Address]
+type Weight6 = WeightOf[Address6]
+
+// A cost in which each address in a weighted interval costs its weight
+// and every other address costs defaultWeight.  Weighted intervals must
+// not overlap, and the cost of the whole address space at any weight must
+// fit in A.
+func WeightedCost[A Addr[A]](defaultWeight uint64, weights []WeightOf[A]) (CostOf[A], error) {
+	if err := checkWeight[A](defaultWeight); err != nil {
+		return nil, err
+	}
+	sorted := make([]WeightOf[A], 0, len(weights))
+	for _, w := range weights {
+		if w.Interval.Right.Less(w.Interval.Left) {
+			return nil, fmt.Errorf("invalid interval [%v, %v)", w.Interval.Left, w.Interval.Right)
+		}
+		if err := checkWeight[A](w.Weight); err != nil {
+			return nil, err
+		}
+		if w.Interval.Left != w.Interval.Right {
+			sorted = append(sorted, w)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Interval.Left.Less(sorted[j].Interval.Left)
+	})
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Interval.Left.Less(sorted[i-1].Interval.Right) {
+			return nil, fmt.Errorf("weighted intervals %v and %v overlap",
+				sorted[i-1].Interval, sorted[i].Interval)
+		}
+	}
+
+	// Cumulative weight and size of the weighted intervals before
+	// each one.
+	cumWeight := make([]A, len(sorted)+1)
+	cumSize := make([]A, len(sorted)+1)
+	for i, w := range sorted {
+		size := w.Interval.Right.Sub(w.Interval.Left)
+		cumWeight[i+1] = cumWeight[i].Add(size.Mul(w.Weight))
+		cumSize[i+1] = cumSize[i].Add(size)
+	}
+
+	// Weight and number of weighted addresses in [0, x).
+	below := func(x A) (A, A) {
+		i := sort.Search(len(sorted), func(j int) bool {
+			return x.Less(sorted[j].Interval.Right)
+		})
+		weight, size := cumWeight[i], cumSize[i]
+		if i < len(sorted) && sorted[i].Interval.Left.Less(x) {
+			partial := x.Sub(sorted[i].Interval.Left)
+			weight = weight.Add(partial.Mul(sorted[i].Weight))
+			size = size.Add(partial)
+		}
+		return weight, size
+	}
+
+	return func(network NetworkOf[A]) A {
+		leftWeight, leftSize := below(network.Left)
+		rightWeight, rightSize := below(network.Right)
+		weighted := rightSize.Sub(leftSize)
+		unweighted := network.Size().Sub(weighted)
+		return rightWeight.Sub(leftWeight).Add(unweighted.Mul(defaultWeight))
+	}, nil
+}
+
+// Check that weight times the size of the address space does not
+// overflow.
+func checkWeight[A Addr[A]](weight uint64) error {
+	var zero A
+	bits := zero.AddressBits()
+	if zero.FromUint64(1).Lsh(bits).Mul(weight).Rsh(bits) != zero.FromUint64(weight) {
+		return fmt.Errorf("weight %d overflows the cost of %d-bit addresses", weight, bits)
+	}
+	return nil
+}
+
+// Determine the cost of the union of a list of `Interval`s.
+func FootprintCost[A Addr[A]](input []IntervalOf[A], cost CostOf[A]) A {
+	var total A
+	for _, network := range DecomposeAll(input) {
+		total = total.Add(cost(network))
+	}
+	return total
+}
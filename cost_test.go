@@ -0,0 +1,124 @@
+package boundednet_test
+
+import (
+	"fmt"
+	bn "github.com/fhltang/boundednet"
+	"math"
+	"testing"
+)
+
+func TestWeightedCost(t *testing.T) {
+	cost, err := bn.WeightedCost(1, []bn.Weight{
+		{bn.Interval{8, 12}, 10},
+		{bn.Interval{0, 2}, 0},
+		{bn.Interval{20, 20}, 5},
+	})
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	type Case struct {
+		Input    bn.Network
+		Expected bn.Address
+	}
+	cases := []Case{
+		{bn.Network{0, 1}, 0},
+		{bn.Network{0, 4}, 2},
+		{bn.Network{4, 8}, 4},
+		{bn.Network{8, 10}, 20},
+		{bn.Network{10, 11}, 10},
+		{bn.Network{0, 16}, 2 + 4 + 40 + 4},
+		{bn.Network{16, 32}, 16},
+		{bn.Network{0, 1 << 32}, 1<<32 - 6 + 40},
+		{bn.EmptyNetwork(), 0},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("[%d, %d)", tc.Input.Left, tc.Input.Right), func(t *testing.T) {
+			if c := cost(tc.Input); c != tc.Expected {
+				t.Error("Expected", uint64(tc.Expected), "got", uint64(c))
+			}
+		})
+	}
+}
+
+func TestWeightedCost_Overlap(t *testing.T) {
+	_, err := bn.WeightedCost(1, []bn.Weight{
+		{bn.Interval{8, 12}, 10},
+		{bn.Interval{0, 9}, 0},
+	})
+	if err == nil {
+		t.Error("Expected error for overlapping weights")
+	}
+}
+
+func TestWeightedCost_Overflow(t *testing.T) {
+	type Case struct {
+		Name    string
+		Default uint64
+		Weight  uint64
+		Valid   bool
+	}
+	cases := []Case{
+		{"Largest", 1, 1<<32 - 1, true},
+		{"Weight", 1, 1 << 33, false},
+		{"MaxWeight", 1, math.MaxUint64, false},
+		{"Default", 1 << 32, 1, false},
+	}
+	for _, c := range cases {
+		_, err := bn.WeightedCost(c.Default, []bn.Weight{{bn.Interval{1 << 31, 1 << 32}, c.Weight}})
+		if (err == nil) != c.Valid {
+			t.Error(c.Name, "Expected valid", c.Valid, "got", err)
+		}
+	}
+
+	// Any weight is valid for IPv6.
+	if _, err := bn.WeightedCost(math.MaxUint64, []bn.Weight6{}); err != nil {
+		t.Error("Unexpected error", err)
+	}
+}
+
+func TestWeightedCost6(t *testing.T) {
+	expensive := bn.ParseNetwork6("2001:db8::/32")
+	cost, err := bn.WeightedCost(1, []bn.Weight6{{bn.Interval6(expensive), 1 << 40}})
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	expected := expensive.Size().Mul(1 << 40)
+	if c := cost(expensive); c != expected {
+		t.Error("Expected", expected, "got", c)
+	}
+	if c := cost(bn.ParseNetwork6("::/0")); c != bn.ParseNetwork6("::/0").Size().Sub(expensive.Size()).Add(expected) {
+		t.Error("Unexpected cost", c)
+	}
+}
+
+func TestAddress6Mul(t *testing.T) {
+	type Case struct {
+		Input    bn.Address6
+		X        uint64
+		Expected bn.Address6
+	}
+	cases := []Case{
+		{bn.Address6{Lo: 3}, 5, bn.Address6{Lo: 15}},
+		{bn.Address6{Lo: 1 << 63}, 4, bn.Address6{Hi: 2}},
+		{bn.Address6{Hi: 1 << 63, Lo: 1 << 63}, 2, bn.Address6{Top: 1, Hi: 1}},
+		{bn.Address6{Top: 1}, 3, bn.Address6{Top: 3}},
+	}
+	for _, tc := range cases {
+		if m := tc.Input.Mul(tc.X); m != tc.Expected {
+			t.Error("Expected", tc.Expected, "got", m)
+		}
+	}
+}
+
+func TestFootprintCost(t *testing.T) {
+	cost, _ := bn.WeightedCost(2, []bn.Weight{{bn.Interval{4, 8}, 0}})
+	input := []bn.Interval{{1, 6}, {5, 10}, {12, 13}}
+	// [1, 4) and [8, 10) and [12, 13) cost 2 per address.
+	if c := bn.FootprintCost(input, cost); c != 12 {
+		t.Error("Expected 12 got", uint64(c))
+	}
+	if c := bn.FootprintCost(input, bn.SizeCost[bn.Address]); c != bn.FootprintSize(input) {
+		t.Error("Expected", uint64(bn.FootprintSize(input)), "got", uint64(c))
+	}
+}
@@ -0,0 +1,161 @@
+package boundednet_test
+
+import (
+	bn "github.com/fhltang/boundednet"
+	"github.com/fhltang/boundednet/binary"
+	"github.com/fhltang/boundednet/snoc"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Decode networks from fuzzer bytes: each network is a 4-byte address
+// followed by a prefix length, with host bits masked away.
+func fuzzNetworks(data []byte, max int) []bn.Network {
+	networks := []bn.Network{}
+	for ; len(data) >= 5 && len(networks) < max; data = data[5:] {
+		size := bn.Address(1) << (32 - uint(data[4])%33)
+		left := bn.Address(data[0])<<24 | bn.Address(data[1])<<16 | bn.Address(data[2])<<8 | bn.Address(data[3])
+		left -= left % size
+		networks = append(networks, bn.Network{left, left + size})
+	}
+	return networks
+}
+
+func checkCanonical(t *testing.T, intervals []bn.Interval) {
+	for i, intvl := range intervals {
+		if !intvl.Left.Less(intvl.Right) {
+			t.Fatal("empty interval", intvl, "in", intervals)
+		}
+		if i > 0 && !intervals[i-1].Right.Less(intvl.Left) {
+			t.Fatal("intervals not separated", intervals)
+		}
+	}
+}
+
+func FuzzParseNetworkE(f *testing.F) {
+	for _, s := range []string{"192.168.1.0/24", "10.0.0.1/32", "0.0.0.0/0", "1.2.3.4/33", "1.2.3/8", ""} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		network, err := bn.ParseNetworkE(s)
+		if err != nil {
+			return
+		}
+		if !network.Valid() || network.Size() == 0 {
+			t.Fatal(s, "parsed to invalid network", network)
+		}
+		again, err := bn.ParseNetworkE(network.String())
+		if err != nil || again != network {
+			t.Fatal(s, "parsed to", network, "which reparsed to", again, err)
+		}
+	})
+}
+
+func FuzzParseIntervals(f *testing.F) {
+	for _, s := range []string{
+		"10.0.0.5-10.0.0.200\n10.0.1.0/24\n",
+		"# comment\n\n  192.168.0.0/16  # trailing\n",
+		"10.0.0.2-10.0.0.1\n",
+		"2001:db8::/32\n",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		intervals, err := bn.ParseIntervals(strings.NewReader(s))
+		if err != nil {
+			return
+		}
+		for _, intvl := range intervals {
+			if intvl.Right.Less(intvl.Left) {
+				t.Fatal(s, "parsed to invalid interval", intvl)
+			}
+		}
+		networks := bn.NormaliseIntervals(intervals)
+		if !bn.Subset(intervals, bn.IntervalSlice(networks)) || !bn.Subset(bn.IntervalSlice(networks), intervals) {
+			t.Fatal(s, "normalised to", networks, "with a different footprint")
+		}
+	})
+}
+
+func FuzzCanonical(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 1, 5, 0, 0, 0, 2, 7, 255, 255, 255, 255, 200})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Intervals are any pair of addresses, including empty and
+		// reversed ones, which Canonical drops.
+		var input []bn.Interval
+		for ; len(data) >= 2; data = data[2:] {
+			input = append(input, bn.Interval{bn.Address(data[0]), bn.Address(data[1])})
+		}
+		canonical := bn.Canonical(input)
+		checkCanonical(t, canonical)
+
+		var nonEmpty []bn.Interval
+		for _, intvl := range input {
+			if intvl.Left.Less(intvl.Right) {
+				nonEmpty = append(nonEmpty, intvl)
+			}
+		}
+		if !bn.Subset(nonEmpty, canonical) || !bn.Subset(canonical, nonEmpty) {
+			t.Fatal(input, "has canonical form", canonical, "with a different union")
+		}
+	})
+}
+
+func FuzzNormaliseInput(f *testing.F) {
+	f.Add([]byte{10, 0, 0, 0, 8, 10, 1, 0, 0, 16, 10, 2, 0, 0, 16, 192, 168, 0, 0, 24})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		input := fuzzNetworks(data, 1000)
+		normalised := bn.NormaliseInput(input)
+		if !sort.IsSorted(bn.ByLeftWidth[bn.Address](normalised)) {
+			t.Fatal(input, "normalised to unsorted", normalised)
+		}
+		for i := 1; i < len(normalised); i++ {
+			if normalised[i].Left.Less(normalised[i-1].Right) {
+				t.Fatal(input, "normalised to overlapping", normalised)
+			}
+		}
+		if !bn.Subset(bn.IntervalSlice(input), bn.IntervalSlice(normalised)) ||
+			!bn.Subset(bn.IntervalSlice(normalised), bn.IntervalSlice(input)) {
+			t.Fatal(input, "normalised to", normalised, "with a different footprint")
+		}
+	})
+}
+
+func FuzzSolvers(f *testing.F) {
+	f.Add([]byte{10, 0, 0, 0, 24, 10, 0, 1, 0, 24, 10, 0, 3, 0, 24, 192, 168, 0, 1, 32}, uint8(2))
+	f.Add([]byte{0, 0, 0, 0, 0}, uint8(1))
+	f.Fuzz(func(t *testing.T, data []byte, m uint8) {
+		input := fuzzNetworks(data, 64)
+		if len(input) == 0 || m == 0 {
+			return
+		}
+
+		var size bn.Address
+		for name, solve := range map[string]bn.Solver{
+			"snoc":          snoc.Solve[bn.Address],
+			"snoc-linear":   snoc.SolveLinear[bn.Address],
+			"snoc-monotone": snoc.SolveMonotone[bn.Address],
+			"binary":        binary.Solve[bn.Address],
+			"binary-arena":  binary.SolveArena[bn.Address],
+		} {
+			solution := solve(input, int(m))
+			if len(solution) > int(m) {
+				t.Fatal(name, "returned", len(solution), "networks for M =", m)
+			}
+			for _, network := range solution {
+				if !network.Valid() {
+					t.Fatal(name, "returned invalid network", network)
+				}
+			}
+			if !bn.Subset(bn.IntervalSlice(input), bn.IntervalSlice(solution)) {
+				t.Fatal(name, "solution", solution, "does not cover", input)
+			}
+			s := bn.FootprintSize(bn.IntervalSlice(solution))
+			if size != 0 && s != size {
+				t.Fatal("solvers disagree on footprint size for", input, "M =", m)
+			}
+			size = s
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package boundednet
+
+import (
+	"fmt"
+)
+
+// Input networks summarised separately from those of other groups.
+type GroupOf[A Addr[A]] struct {
+	Input []NetworkOf[A]
+
+	// Bound on the number of networks of the group's solution, or 0
+	// for only the overall bound.
+	M int
+}
+
+type Group = GroupOf[Address]
+type Group6 = GroupOf[Address6]
+
+// Function type of SolveFrontier of the solver packages.
+type FrontierSolverOf[A Addr[A]] func(input []NetworkOf[A], m int) []FrontierPointOf[A]
+
+type FrontierSolver = FrontierSolverOf[Address]
+type FrontierSolver6 = FrontierSolverOf[Address6]
+
+// Solve a bounded network problem for each group, with at most m networks
+// in total, minimising the total cost.  Returns the point of each group's
+// frontier chosen, whose M is the number of networks given to the group.
+//
+// The frontier of each group gives its minimal cost for every bound, so
+// the bound m is distributed by a knapsack over the groups taking
+// O(G * m^2) for G groups.
+func SolveGroups[A Addr[A]](groups []GroupOf[A], m int, frontier FrontierSolverOf[A]) ([]FrontierPointOf[A], error) {
+	if m <= 0 {
+		return nil, fmt.Errorf("M=%d: %w", m, ErrInvalidBound)
+	}
+
+	curves := make([][]FrontierPointOf[A], len(groups))
+	for g, group := range groups {
+		if group.M < 0 {
+			return nil, fmt.Errorf("group %d: M=%d: %w", g, group.M, ErrInvalidBound)
+		}
+		bound := m
+		if group.M > 0 {
+			bound = min(bound, group.M)
+		}
+		if err := ValidateProblem(group.Input, bound); err != nil {
+			return nil, fmt.Errorf("group %d: %w", g, err)
+		}
+		if len(group.Input) == 0 {
+			curves[g] = []FrontierPointOf[A]{{Solution: []NetworkOf[A]{}}}
+			continue
+		}
+		curves[g] = frontier(group.Input, bound)
+	}
+
+	// After g groups, cost[k] is the minimal total cost of the first g
+	// groups with k networks, if feasible[k].  choice[g][k] is the
+	// point of group g's curve attaining it.
+	cost := make([]A, m+1)
+	feasible := make([]bool, m+1)
+	feasible[0] = true
+	choice := make([][]int, len(groups))
+	for g, curve := range curves {
+		nextCost := make([]A, m+1)
+		nextFeasible := make([]bool, m+1)
+		choice[g] = make([]int, m+1)
+		for k := 0; k <= m; k++ {
+			for i, point := range curve {
+				j := k - point.M
+				if j < 0 || !feasible[j] {
+					continue
+				}
+				c := cost[j].Add(point.MinSize)
+				if !nextFeasible[k] || c.Less(nextCost[k]) {
+					nextCost[k], nextFeasible[k], choice[g][k] = c, true, i
+				}
+			}
+		}
+		cost, feasible = nextCost, nextFeasible
+	}
+
+	best := -1
+	for k := 0; k <= m; k++ {
+		if feasible[k] && (best < 0 || cost[k].Less(cost[best])) {
+			best = k
+		}
+	}
+	if best < 0 {
+		return nil, ErrInfeasible
+	}
+
+	result := make([]FrontierPointOf[A], len(groups))
+	for g := len(groups) - 1; g >= 0; g-- {
+		result[g] = curves[g][choice[g][best]]
+		best -= result[g].M
+	}
+	return result, nil
+}
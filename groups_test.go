@@ -0,0 +1,172 @@
+package boundednet_test
+
+import (
+	"errors"
+	"fmt"
+	bn "github.com/fhltang/boundednet"
+	"github.com/fhltang/boundednet/binary"
+	"github.com/fhltang/boundednet/snoc"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type groupSolver struct {
+	Name  string
+	Solve func([]bn.Group, int) ([]bn.FrontierPoint, error)
+}
+
+var groupSolvers = []groupSolver{
+	{"snoc", snoc.SolveGroups[bn.Address]},
+	{"binary", binary.SolveGroups[bn.Address]},
+}
+
+func TestSolveGroups(t *testing.T) {
+	// Two networks save 512 addresses in the first group, but only 256
+	// in the second.
+	first := []bn.Network{
+		bn.ParseNetwork("10.0.0.0/24"),
+		bn.ParseNetwork("10.0.3.0/24"),
+	}
+	second := []bn.Network{
+		bn.ParseNetwork("192.168.0.0/24"),
+		bn.ParseNetwork("192.168.1.0/24"),
+		bn.ParseNetwork("192.168.3.0/24"),
+	}
+	type Case struct {
+		Name        string
+		Groups      []bn.Group
+		M           int
+		Expected    [][]bn.Network
+		ExpectedErr error
+	}
+	cases := []Case{
+		{
+			"one_each",
+			[]bn.Group{{first, 0}, {second, 0}},
+			2,
+			[][]bn.Network{
+				{bn.ParseNetwork("10.0.0.0/22")},
+				{bn.ParseNetwork("192.168.0.0/22")},
+			},
+			nil,
+		},
+		{
+			"first_gets_more",
+			[]bn.Group{{first, 0}, {second, 0}},
+			3,
+			[][]bn.Network{
+				{bn.ParseNetwork("10.0.0.0/24"), bn.ParseNetwork("10.0.3.0/24")},
+				{bn.ParseNetwork("192.168.0.0/22")},
+			},
+			nil,
+		},
+		{
+			"group_limit",
+			[]bn.Group{{first, 1}, {second, 0}},
+			3,
+			[][]bn.Network{
+				{bn.ParseNetwork("10.0.0.0/22")},
+				{bn.ParseNetwork("192.168.0.0/23"), bn.ParseNetwork("192.168.3.0/24")},
+			},
+			nil,
+		},
+		{
+			"empty_group",
+			[]bn.Group{{[]bn.Network{}, 0}, {first, 0}},
+			1,
+			[][]bn.Network{{}, {bn.ParseNetwork("10.0.0.0/22")}},
+			nil,
+		},
+		{"no_groups", []bn.Group{}, 1, [][]bn.Network{}, nil},
+		{"too_many_groups", []bn.Group{{first, 0}, {second, 0}}, 1, nil, bn.ErrInfeasible},
+		{"zero_m", []bn.Group{{first, 0}}, 0, nil, bn.ErrInvalidBound},
+		{"negative_group_m", []bn.Group{{first, -1}}, 2, nil, bn.ErrInvalidBound},
+		{"invalid_network", []bn.Group{{[]bn.Network{{1, 3}}, 0}}, 2, nil, bn.ErrInvalidNetwork},
+	}
+	for _, solver := range groupSolvers {
+		for _, tc := range cases {
+			t.Run(fmt.Sprintf("%s %s", solver.Name, tc.Name), func(t *testing.T) {
+				points, err := solver.Solve(tc.Groups, tc.M)
+				if !errors.Is(err, tc.ExpectedErr) {
+					t.Fatal("Expected error", tc.ExpectedErr, "got", err)
+				}
+				if err != nil {
+					return
+				}
+				solutions := [][]bn.Network{}
+				for _, point := range points {
+					solutions = append(solutions, point.Solution)
+				}
+				if !reflect.DeepEqual(tc.Expected, solutions) {
+					t.Error("Expected", tc.Expected, "got", solutions)
+				}
+			})
+		}
+	}
+}
+
+// Compare SolveGroups with solving each group for every distribution of
+// the bound.
+func TestSolveGroups_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		groups := make([]bn.Group, 1+r.Intn(3))
+		for g := range groups {
+			groups[g].Input = randomInput(r, r.Intn(6))
+			groups[g].M = r.Intn(4)
+		}
+		m := 1 + r.Intn(6)
+
+		// Minimal total cost over every distribution of at most m
+		// networks.
+		var expected bn.Address
+		found := false
+		var search func(g, m int, size bn.Address)
+		search = func(g, m int, size bn.Address) {
+			if g == len(groups) {
+				if !found || size < expected {
+					expected, found = size, true
+				}
+				return
+			}
+			if len(groups[g].Input) == 0 {
+				search(g+1, m, size)
+				return
+			}
+			for k := 1; k <= m && (groups[g].M == 0 || k <= groups[g].M); k++ {
+				solution := snoc.Solve(groups[g].Input, k)
+				search(g+1, m-k, size+bn.FootprintSize(bn.IntervalSlice(solution)))
+			}
+		}
+		search(0, m, 0)
+
+		for _, solver := range groupSolvers {
+			points, err := solver.Solve(groups, m)
+			if !found {
+				if !errors.Is(err, bn.ErrInfeasible) {
+					t.Fatal(solver.Name, "groups", groups, "M =", m, "expected infeasible, got", points, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatal(solver.Name, "groups", groups, "M =", m, "unexpected error", err)
+			}
+			var size bn.Address
+			total := 0
+			for g, point := range points {
+				if !MakeProblem(groups[g].Input).IsPresolution(max(1, point.M), point.Solution) {
+					t.Fatal(solver.Name, "group", groups[g], "got", point)
+				}
+				if groups[g].M != 0 && point.M > groups[g].M {
+					t.Fatal(solver.Name, "group", groups[g], "got", point.M, "networks")
+				}
+				size += bn.FootprintSize(bn.IntervalSlice(point.Solution))
+				total += point.M
+			}
+			if total > m || size != expected {
+				t.Fatal(solver.Name, "groups", groups, "M =", m, "expected size", expected, "got", size, "with", total, "networks")
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package boundednet
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Prefix length of a valid non-empty network.
+func (this NetworkOf[A]) PrefixLength() int {
+	_, _, k := this.Normalise()
+	return int(this.Left.AddressBits() - k)
+}
+
+// Check limits on the prefix length of output networks for a problem.
+// A limit of 0 means there is none.  Returns an error wrapping
+// ErrPrefixLength if the limits are outside the address space or the
+// minimum exceeds the maximum, and ErrInfeasible if covering the input
+// needs more than m networks of at least the minimum prefix length.
+func ValidatePrefixLengths[A Addr[A]](input []NetworkOf[A], minLength, maxLength, m int) error {
+	if minLength == 0 && maxLength == 0 {
+		return nil
+	}
+	var zero A
+	bits := int(zero.AddressBits())
+	if maxLength == 0 {
+		maxLength = bits
+	}
+	if minLength < 0 || maxLength > bits || maxLength < minLength {
+		return fmt.Errorf("prefix lengths /%d to /%d: %w", minLength, maxLength, ErrPrefixLength)
+	}
+
+	// Every network of length minLength containing an input address
+	// needs its own output network.
+	if minLength == 0 {
+		return nil
+	}
+	count := 0
+	for _, network := range LimitPrefixLengths(input, 0, minLength) {
+		// The network needs 2^shift output networks, which exceeds
+		// any int bound if shift >= IntSize-1.
+		shift := minLength - network.PrefixLength()
+		if shift >= strconv.IntSize-1 || 1<<shift > m-count {
+			return fmt.Errorf("input needs more than M=%d networks of prefix length at least /%d: %w",
+				m, minLength, ErrInfeasible)
+		}
+		count += 1 << shift
+	}
+	return nil
+}
+
+// Replace input networks longer than maxLength by the network of that
+// length containing them, and split those shorter than minLength into
+// networks of that length.  A limit of 0 means there is none.  Networks
+// with a prefix length in the limits then cover the input if and only if
+// they cover the result.  The limits must be valid, and the result may
+// have up to 2^minLength networks.
+func LimitPrefixLengths[A Addr[A]](input []NetworkOf[A], minLength, maxLength int) []NetworkOf[A] {
+	var zero A
+	bits := int(zero.AddressBits())
+	if maxLength == 0 {
+		maxLength = bits
+	}
+
+	result := make([]NetworkOf[A], 0, len(input))
+	for _, network := range input {
+		if network.Left == network.Right {
+			continue
+		}
+		length := network.PrefixLength()
+		if length > maxLength {
+			k := uint(bits - maxLength)
+			network = NonEmptyNetworkOf[A]{network.Left.Rsh(k), k}.ToNetwork()
+			length = maxLength
+		}
+		if length >= minLength {
+			result = append(result, network)
+			continue
+		}
+		size := zero.FromUint64(1).Lsh(uint(bits - minLength))
+		for left := network.Left; left.Less(network.Right); left = left.Add(size) {
+			result = append(result, NetworkOf[A]{left, left.Add(size)})
+		}
+	}
+	if len(result) == 0 {
+		return result
+	}
+	return NormaliseInput(result)
+}
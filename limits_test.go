@@ -0,0 +1,96 @@
+package boundednet_test
+
+import (
+	"errors"
+	bn "github.com/fhltang/boundednet"
+	"reflect"
+	"testing"
+)
+
+func TestPrefixLength(t *testing.T) {
+	for _, s := range []string{"0.0.0.0/0", "10.0.0.0/8", "192.168.1.0/24", "10.0.0.1/32"} {
+		var expected int
+		for i := range s {
+			if s[i] == '/' {
+				expected = 0
+				for _, c := range s[i+1:] {
+					expected = 10*expected + int(c-'0')
+				}
+			}
+		}
+		if actual := bn.ParseNetwork(s).PrefixLength(); actual != expected {
+			t.Error(s, "Expected", expected, "got", actual)
+		}
+	}
+	if actual := bn.ParseNetwork6("2001:db8::/32").PrefixLength(); actual != 32 {
+		t.Error("Expected 32, got", actual)
+	}
+}
+
+func TestLimitPrefixLengths(t *testing.T) {
+	type Case struct {
+		Name     string
+		Input    []bn.Network
+		Min, Max int
+		Expected []bn.Network
+	}
+	cases := []Case{
+		{"none", []bn.Network{bn.ParseNetwork("10.0.0.1/32")}, 0, 0, []bn.Network{bn.ParseNetwork("10.0.0.1/32")}},
+		{
+			"widened",
+			[]bn.Network{bn.ParseNetwork("10.0.0.9/32"), bn.ParseNetwork("10.0.0.1/32"), bn.ParseNetwork("10.0.1.0/25")},
+			0, 24,
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/24"), bn.ParseNetwork("10.0.1.0/24")},
+		},
+		{
+			"split",
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/15"), bn.ParseNetwork("10.1.2.0/24")},
+			16, 24,
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/16"), bn.ParseNetwork("10.1.0.0/16")},
+		},
+		{"empty", []bn.Network{{5, 5}}, 8, 24, []bn.Network{}},
+	}
+	for _, c := range cases {
+		actual := bn.LimitPrefixLengths(c.Input, c.Min, c.Max)
+		if !reflect.DeepEqual(c.Expected, actual) {
+			t.Error(c.Name, "Expected", c.Expected, "got", actual)
+		}
+	}
+}
+
+func TestValidatePrefixLengths(t *testing.T) {
+	type Case struct {
+		Name     string
+		Input    []bn.Network
+		M        int
+		Min, Max int
+		Expected error
+	}
+	input := []bn.Network{bn.ParseNetwork("10.0.0.0/15"), bn.ParseNetwork("10.1.2.0/24")}
+	cases := []Case{
+		{"none", input, 1, 0, 0, nil},
+		{"feasible", input, 2, 16, 0, nil},
+		{"infeasible", input, 1, 16, 0, bn.ErrInfeasible},
+		{"huge", []bn.Network{bn.ParseNetwork("0.0.0.0/0")}, 1000, 32, 0, bn.ErrInfeasible},
+		{"max_only", input, 1, 0, 8, nil},
+		{"min_above_max", input, 1, 9, 8, bn.ErrPrefixLength},
+		{"too_long", input, 1, 0, 33, bn.ErrPrefixLength},
+	}
+	for _, c := range cases {
+		if err := bn.ValidatePrefixLengths(c.Input, c.Min, c.Max, c.M); !errors.Is(err, c.Expected) {
+			t.Error(c.Name, "Expected", c.Expected, "got", err)
+		}
+	}
+
+	// ::/0 needs exactly 2^40 networks of length /40.
+	input6 := []bn.Network6{bn.ParseNetwork6("::/0")}
+	if err := bn.ValidatePrefixLengths(input6, 40, 0, 1<<40); err != nil {
+		t.Error("Unexpected error", err)
+	}
+	if err := bn.ValidatePrefixLengths(input6, 40, 0, 1<<40-1); !errors.Is(err, bn.ErrInfeasible) {
+		t.Error("Expected", bn.ErrInfeasible, "got", err)
+	}
+	if err := bn.ValidatePrefixLengths(input6, 128, 0, 1<<62); !errors.Is(err, bn.ErrInfeasible) {
+		t.Error("Expected", bn.ErrInfeasible, "got", err)
+	}
+}
@@ -2,114 +2,155 @@ package boundednet
 
 import (
 	"fmt"
+	"math/bits"
 	"sort"
 	"strconv"
-	"strings"
 )
 
+// An unsigned integer type used to represent addresses of some address
+// space [0, 2^AddressBits()).
+//
+// Implementations must be able to hold 2^AddressBits() itself (so that
+// networks can be expressed as closed-open intervals) as well as sums of
+// network sizes, which the solvers compute.  Since Go has no operator
+// overloading, arithmetic is expressed as methods.
+type Addr[A any] interface {
+	comparable
+
+	Add(A) A
+	Sub(A) A
+	Mul(uint64) A
+	Less(A) bool
+	Lsh(uint) A
+	Rsh(uint) A
+
+	// Number of trailing zero bits.  The zero value has at least
+	// AddressBits() trailing zeros.
+	TrailingZeros() uint
+
+	// Number of bits in an address of this address space.
+	AddressBits() uint
+
+	// Convert a small constant to this type.  The receiver is
+	// ignored; this allows generic code to construct constants.
+	FromUint64(uint64) A
+
+	// Textual representation of an address.
+	String() string
+
+	// Decimal representation, for sizes and costs.
+	Decimal() string
+}
+
 // For convenience, we allow 2^32 as an "address" since this allows us
 // to express networks as closed-open intervals.
 type Address uint64
 
+func (this Address) Add(that Address) Address    { return this + that }
+func (this Address) Sub(that Address) Address    { return this - that }
+func (this Address) Mul(x uint64) Address        { return this * Address(x) }
+func (this Address) Less(that Address) bool      { return this < that }
+func (this Address) Lsh(n uint) Address          { return this << n }
+func (this Address) Rsh(n uint) Address          { return this >> n }
+func (this Address) TrailingZeros() uint         { return uint(bits.TrailingZeros64(uint64(this))) }
+func (this Address) AddressBits() uint           { return 32 }
+func (this Address) FromUint64(x uint64) Address { return Address(x) }
+
+func (this Address) Decimal() string {
+	return strconv.FormatUint(uint64(this), 10)
+}
+
+// Format as a dotted quad.
+func (this Address) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d",
+		this>>24, (this>>16)&0xff, (this>>8)&0xff, this&0xff)
+}
+
 // A closed-open interval [Left, Right).
-type Interval struct {
-	Left  Address
-	Right Address
+type IntervalOf[A Addr[A]] struct {
+	Left  A
+	Right A
 }
 
 // A network represented as a closed-open interval [Left, Right).
-type Network Interval
+type NetworkOf[A Addr[A]] IntervalOf[A]
+
+// IPv4 intervals and networks.
+type Interval = IntervalOf[Address]
+type Network = NetworkOf[Address]
 
 func EmptyNetwork() Network {
 	return Network{}
 }
 
+// Parse an IPv4 network such as 192.168.1.0/24, masking away host bits.
+// Panics if the input cannot be parsed; see ParseNetworkE.
 func ParseNetwork(netmask string) Network {
-	addrAndMask := strings.Split(netmask, "/")
-	if len(addrAndMask) != 2 {
-		panic(fmt.Sprintf("Cannot parse input %s", netmask))
-	}
-	addr, mask := addrAndMask[0], addrAndMask[1]
-
-	ones, err := strconv.ParseUint(mask, 10, 0)
+	network, err := ParseNetworkE(netmask)
 	if err != nil {
-		panic(fmt.Sprintf("Cannot parse number %s", mask))
+		panic(fmt.Sprintf("Cannot parse input %s: %v", netmask, err))
 	}
-
-	bytes := strings.Split(addr, ".")
-	if len(bytes) != 4 {
-		panic(fmt.Sprintf("Cannot parse address %s", bytes))
-	}
-	var left, right uint64
-	for _, b := range bytes {
-		left = left << 8
-		byte, err := strconv.ParseUint(b, 10, 64)
-		if err != nil {
-			panic(fmt.Sprintf("Cannot parse byte value %s", b))
-		}
-		left = left + byte
-	}
-	bitMask := uint64(((1 << ones) - 1) << (32 - ones))
-	left = left & bitMask
-	right = left + (uint64(1) << (32 - ones))
-	return Network{Address(left), Address(right)}
+	return network
 }
 
-func (this Network) Size() int {
-	return int(this.Right - this.Left)
+func (this NetworkOf[A]) Size() A {
+	return this.Right.Sub(this.Left)
 }
 
 // Normalise network into form [x*2^k, y*2^k) for largest k.
-func (this Network) Normalise() (int, int, uint) {
+func (this NetworkOf[A]) Normalise() (A, A, uint) {
 	if this.Left == this.Right {
-		return 0, 0, 0
+		var zero A
+		return zero, zero, 0
 	}
 	k := uint(0)
-	x := int(this.Left)
-	y := int(this.Right)
-	for x != y && x%2 == 0 && y%2 == 0 {
+	x := this.Left
+	y := this.Right
+	for x != y && x.TrailingZeros() > 0 && y.TrailingZeros() > 0 {
 		k++
-		x = x >> 1
-		y = y >> 1
+		x = x.Rsh(1)
+		y = y.Rsh(1)
 	}
 	return x, y, k
 }
 
 // Determine if a network is valid.
-func (this Network) Valid() bool {
-	if this.Right < this.Left {
+func (this NetworkOf[A]) Valid() bool {
+	if this.Right.Less(this.Left) {
 		return false
 	}
 
-	if this.Size() == 0 {
+	if this.Left == this.Right {
 		return true
 	}
 
 	// Try to find a, k such that this == [a*2^k, (a+1)*2^k)
 	a, a1, _ := this.Normalise()
-	return a+1 == a1
+	return a.Add(a.FromUint64(1)) == a1
 }
 
 // Alternative representation of network [A*2^K, (A+1)*2^K)
-type NonEmptyNetwork struct {
-	A int
+type NonEmptyNetworkOf[A Addr[A]] struct {
+	A A
 	K uint
 }
 
-func (this Network) ToNonEmptyNetwork() NonEmptyNetwork {
-	if !this.Valid() || this.Size() == 0 {
+type NonEmptyNetwork = NonEmptyNetworkOf[Address]
+
+func (this NetworkOf[A]) ToNonEmptyNetwork() NonEmptyNetworkOf[A] {
+	if !this.Valid() || this.Left == this.Right {
 		panic("Not a valid non-empty network")
 	}
 
 	// Find a, k such that this == [a*2^k, (a+1)*2^k)
 	a, _, k := this.Normalise()
-	return NonEmptyNetwork{A: a, K: k}
+	return NonEmptyNetworkOf[A]{A: a, K: k}
 }
 
-func (this NonEmptyNetwork) ToNetwork() Network {
-	return Network{
-		Left:  Address(this.A * (1 << this.K)),
-		Right: Address((this.A + 1) * (1 << this.K)),
+func (this NonEmptyNetworkOf[A]) ToNetwork() NetworkOf[A] {
+	return NetworkOf[A]{
+		Left:  this.A.Lsh(this.K),
+		Right: this.A.Add(this.A.FromUint64(1)).Lsh(this.K),
 	}
 }
 
@@ -117,32 +158,36 @@ func (this NonEmptyNetwork) ToNetwork() Network {
 //
 // Given a list of Interval, return the shortest list of intervals in
 // increasing order whose union is the same as that of the input list.
-type CanonicalOrder []Interval
+type CanonicalOrder[A Addr[A]] []IntervalOf[A]
 
-func (this CanonicalOrder) Len() int      { return len(this) }
-func (this CanonicalOrder) Swap(i, j int) { this[i], this[j] = this[j], this[i] }
-func (this CanonicalOrder) Less(i, j int) bool {
+func (this CanonicalOrder[A]) Len() int      { return len(this) }
+func (this CanonicalOrder[A]) Swap(i, j int) { this[i], this[j] = this[j], this[i] }
+func (this CanonicalOrder[A]) Less(i, j int) bool {
 	if this[i].Left == this[j].Left {
-		return this[i].Right > this[j].Right
+		return this[j].Right.Less(this[i].Right)
 	}
-	return this[i].Left < this[j].Left
+	return this[i].Left.Less(this[j].Left)
 }
 
-func Canonical(input []Interval) []Interval {
-	if len(input) == 0 {
-		return []Interval{}
+func Canonical[A Addr[A]](input []IntervalOf[A]) []IntervalOf[A] {
+	result := make([]IntervalOf[A], 0, len(input))
+	for _, intvl := range input {
+		if intvl.Left.Less(intvl.Right) {
+			result = append(result, intvl)
+		}
+	}
+	if len(result) == 0 {
+		return result
 	}
 
-	result := make([]Interval, len(input))
-	copy(result, input)
-	sort.Sort(CanonicalOrder(result))
+	sort.Sort(CanonicalOrder[A](result))
 	i := 0
 	for j, next := range result[1:] {
 		prev := result[i]
-		if next.Left > prev.Right {
+		if prev.Right.Less(next.Left) {
 			i++
 			result[i], prev = result[j+1], next
-		} else if next.Right > prev.Right {
+		} else if prev.Right.Less(next.Right) {
 			result[i].Right = next.Right
 		}
 	}
@@ -151,36 +196,45 @@ func Canonical(input []Interval) []Interval {
 }
 
 // Determine if a list of `Interval`s is a subset of another.
-func Subset(x, y []Interval) bool {
+func Subset[A Addr[A]](x, y []IntervalOf[A]) bool {
 	cy := Canonical(y)
 	for _, intvl := range x {
 		i := sort.Search(len(cy), func(j int) bool {
-			return cy[j].Left > intvl.Left
+			return intvl.Left.Less(cy[j].Left)
 		})
 		if i == 0 {
 			return false
 		}
-		if intvl.Right > cy[i-1].Right {
+		if cy[i-1].Right.Less(intvl.Right) {
 			return false
 		}
 	}
 	return true
 }
 
+// Determine if an interval intersects the union of a list of intervals
+// returned by Canonical.
+func Intersects[A Addr[A]](x IntervalOf[A], canonical []IntervalOf[A]) bool {
+	i := sort.Search(len(canonical), func(j int) bool {
+		return x.Left.Less(canonical[j].Right)
+	})
+	return i < len(canonical) && canonical[i].Left.Less(x.Right) && x.Left.Less(x.Right)
+}
+
 // Determine the size of the union of a list of `Interval`s.
-func FootprintSize(input []Interval) int {
-	size := 0
+func FootprintSize[A Addr[A]](input []IntervalOf[A]) A {
+	var size A
 	for _, intvl := range Canonical(input) {
-		size = size + int(intvl.Right-intvl.Left)
+		size = size.Add(intvl.Right.Sub(intvl.Left))
 	}
 	return size
 }
 
 // Function to help convert []Network to []Interval.  Stupid that we need this at all.
-func IntervalSlice(input []Network) []Interval {
-	result := make([]Interval, 0, len(input))
+func IntervalSlice[A Addr[A]](input []NetworkOf[A]) []IntervalOf[A] {
+	result := make([]IntervalOf[A], 0, len(input))
 	for _, n := range input {
-		result = append(result, Interval(n))
+		result = append(result, IntervalOf[A](n))
 	}
 	return result
 }
@@ -0,0 +1,145 @@
+package boundednet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"net/netip"
+)
+
+// An IPv6 address as an unsigned 192-bit integer.
+//
+// Addresses occupy the low 128 bits (Hi and Lo).  Top holds any bits
+// above that, which allows us to express 2^128 as an "address" and lets
+// the solvers add network sizes without overflowing.
+type Address6 struct {
+	Top, Hi, Lo uint64
+}
+
+func (this Address6) Add(that Address6) Address6 {
+	lo, c := bits.Add64(this.Lo, that.Lo, 0)
+	hi, c := bits.Add64(this.Hi, that.Hi, c)
+	top, _ := bits.Add64(this.Top, that.Top, c)
+	return Address6{Top: top, Hi: hi, Lo: lo}
+}
+
+func (this Address6) Sub(that Address6) Address6 {
+	lo, b := bits.Sub64(this.Lo, that.Lo, 0)
+	hi, b := bits.Sub64(this.Hi, that.Hi, b)
+	top, _ := bits.Sub64(this.Top, that.Top, b)
+	return Address6{Top: top, Hi: hi, Lo: lo}
+}
+
+func (this Address6) Mul(x uint64) Address6 {
+	carry, lo := bits.Mul64(this.Lo, x)
+	hiCarry, hi := bits.Mul64(this.Hi, x)
+	top := this.Top * x
+	hi, c := bits.Add64(hi, carry, 0)
+	top, _ = bits.Add64(top, hiCarry, c)
+	return Address6{Top: top, Hi: hi, Lo: lo}
+}
+
+func (this Address6) Less(that Address6) bool {
+	if this.Top != that.Top {
+		return this.Top < that.Top
+	}
+	if this.Hi != that.Hi {
+		return this.Hi < that.Hi
+	}
+	return this.Lo < that.Lo
+}
+
+func (this Address6) words() [3]uint64 {
+	return [3]uint64{this.Lo, this.Hi, this.Top}
+}
+
+func fromWords(w [3]uint64) Address6 {
+	return Address6{Top: w[2], Hi: w[1], Lo: w[0]}
+}
+
+func (this Address6) Lsh(n uint) Address6 {
+	w := this.words()
+	var r [3]uint64
+	s, b := int(n/64), n%64
+	for i := 2; i >= s; i-- {
+		r[i] = w[i-s] << b
+		if b > 0 && i-s > 0 {
+			r[i] |= w[i-s-1] >> (64 - b)
+		}
+	}
+	return fromWords(r)
+}
+
+func (this Address6) Rsh(n uint) Address6 {
+	w := this.words()
+	var r [3]uint64
+	s, b := int(n/64), n%64
+	for i := 0; i+s <= 2; i++ {
+		r[i] = w[i+s] >> b
+		if b > 0 && i+s < 2 {
+			r[i] |= w[i+s+1] << (64 - b)
+		}
+	}
+	return fromWords(r)
+}
+
+func (this Address6) TrailingZeros() uint {
+	if this.Lo != 0 {
+		return uint(bits.TrailingZeros64(this.Lo))
+	}
+	if this.Hi != 0 {
+		return 64 + uint(bits.TrailingZeros64(this.Hi))
+	}
+	return 128 + uint(bits.TrailingZeros64(this.Top))
+}
+
+func (this Address6) AddressBits() uint { return 128 }
+
+func (this Address6) FromUint64(x uint64) Address6 { return Address6{Lo: x} }
+
+func (this Address6) Decimal() string {
+	x := new(big.Int).SetUint64(this.Top)
+	x.Lsh(x, 64).Or(x, new(big.Int).SetUint64(this.Hi))
+	x.Lsh(x, 64).Or(x, new(big.Int).SetUint64(this.Lo))
+	return x.String()
+}
+
+// Format in RFC 5952 notation.  Bits above the low 128 are ignored.
+func (this Address6) String() string {
+	return this.addr().String()
+}
+
+func address6(addr netip.Addr) Address6 {
+	b := addr.As16()
+	return Address6{
+		Hi: binary.BigEndian.Uint64(b[:8]),
+		Lo: binary.BigEndian.Uint64(b[8:]),
+	}
+}
+
+func (this Address6) addr() netip.Addr {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], this.Hi)
+	binary.BigEndian.PutUint64(b[8:], this.Lo)
+	return netip.AddrFrom16(b)
+}
+
+// IPv6 intervals and networks.
+type Interval6 = IntervalOf[Address6]
+type Network6 = NetworkOf[Address6]
+type NonEmptyNetwork6 = NonEmptyNetworkOf[Address6]
+
+func EmptyNetwork6() Network6 {
+	return Network6{}
+}
+
+// Parse an IPv6 network such as 2001:db8::/32, masking away host bits.
+// Panics if the input cannot be parsed; see ParseNetwork6E.
+func ParseNetwork6(netmask string) Network6 {
+	network, err := ParseNetwork6E(netmask)
+	if err != nil {
+		panic(fmt.Sprintf("Cannot parse input %s: %v", netmask, err))
+	}
+	return network
+}
@@ -0,0 +1,176 @@
+package boundednet_test
+
+import (
+	"fmt"
+	bn "github.com/fhltang/boundednet"
+	"testing"
+)
+
+func TestEmptyNetwork6Valid(t *testing.T) {
+	if !bn.EmptyNetwork6().Valid() {
+		t.Fail()
+	}
+}
+
+func TestParseNetwork6(t *testing.T) {
+	type Case struct {
+		Input    string
+		Expected bn.Network6
+	}
+	cases := []Case{
+		{
+			"2001:db8::/32",
+			bn.Network6{
+				bn.Address6{Hi: 0x20010db8 << 32},
+				bn.Address6{Hi: 0x20010db9 << 32},
+			},
+		},
+		{
+			"2001:db8::1/128",
+			bn.Network6{
+				bn.Address6{Hi: 0x20010db8 << 32, Lo: 1},
+				bn.Address6{Hi: 0x20010db8 << 32, Lo: 2},
+			},
+		},
+		{
+			"2001:db8::1/64",
+			bn.Network6{
+				bn.Address6{Hi: 0x20010db8 << 32},
+				bn.Address6{Hi: 0x20010db8<<32 + 1},
+			},
+		},
+		{
+			"::/0",
+			bn.Network6{bn.Address6{}, bn.Address6{Top: 1}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Input, func(t *testing.T) {
+			net := bn.ParseNetwork6(tc.Input)
+			if tc.Expected != net {
+				t.Error("expecting", tc.Expected, "got", net)
+			}
+		})
+	}
+}
+
+func TestAddress6String(t *testing.T) {
+	left := bn.ParseNetwork6("2001:db8::1/128").Left
+	if left.String() != "2001:db8::1" {
+		t.Error("expecting 2001:db8::1 got", left)
+	}
+}
+
+func TestAddress6Shift(t *testing.T) {
+	type Case struct {
+		Input    bn.Address6
+		N        uint
+		Expected bn.Address6
+	}
+	cases := []Case{
+		{bn.Address6{Lo: 1}, 0, bn.Address6{Lo: 1}},
+		{bn.Address6{Lo: 1}, 63, bn.Address6{Lo: 1 << 63}},
+		{bn.Address6{Lo: 1}, 64, bn.Address6{Hi: 1}},
+		{bn.Address6{Lo: 3}, 127, bn.Address6{Top: 1, Hi: 1 << 63}},
+		{bn.Address6{Lo: 1}, 128, bn.Address6{Top: 1}},
+		{bn.Address6{Lo: 1}, 192, bn.Address6{}},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%v<<%d", tc.Input, tc.N), func(t *testing.T) {
+			if shifted := tc.Input.Lsh(tc.N); shifted != tc.Expected {
+				t.Error("Lsh: expecting", tc.Expected, "got", shifted)
+			}
+			if tc.Expected == (bn.Address6{}) {
+				return
+			}
+			if shifted := tc.Expected.Rsh(tc.N); shifted != tc.Input {
+				t.Error("Rsh: expecting", tc.Input, "got", shifted)
+			}
+		})
+	}
+}
+
+func TestAddress6Arithmetic(t *testing.T) {
+	max := bn.Address6{Hi: ^uint64(0), Lo: ^uint64(0)}
+	one := bn.Address6{Lo: 1}
+	if sum := max.Add(one); sum != (bn.Address6{Top: 1}) {
+		t.Error("expecting carry into Top, got", sum)
+	}
+	if diff := (bn.Address6{Top: 1}).Sub(one); diff != max {
+		t.Error("expecting borrow from Top, got", diff)
+	}
+	if !max.Less(bn.Address6{Top: 1}) || (bn.Address6{Top: 1}).Less(max) {
+		t.Error("Less does not respect Top")
+	}
+}
+
+func TestValid6(t *testing.T) {
+	validCases := []bn.Network6{
+		bn.ParseNetwork6("::/0"),
+		bn.ParseNetwork6("2001:db8::/32"),
+		bn.ParseNetwork6("2001:db8::1/128"),
+	}
+	for _, tc := range validCases {
+		t.Run(fmt.Sprintf("Valid [%v, %v)", tc.Left, tc.Right),
+			func(t *testing.T) {
+				if !tc.Valid() {
+					t.Fail()
+				}
+			})
+	}
+
+	notValidCases := []bn.Network6{
+		bn.Network6{Left: bn.Address6{Lo: 2}, Right: bn.Address6{Lo: 1}},
+		bn.Network6{Left: bn.Address6{Lo: 8}, Right: bn.Address6{Lo: 11}},
+		bn.Network6{Left: bn.Address6{Lo: 1}, Right: bn.Address6{Top: 1}},
+	}
+	for _, tc := range notValidCases {
+		t.Run(fmt.Sprintf("Not valid [%v, %v)", tc.Left, tc.Right),
+			func(t *testing.T) {
+				if tc.Valid() {
+					t.Fail()
+				}
+			})
+	}
+}
+
+func TestToNonEmptyNetwork6(t *testing.T) {
+	type Case struct {
+		Input    bn.Network6
+		Expected bn.NonEmptyNetwork6
+	}
+	cases := []Case{
+		{
+			bn.ParseNetwork6("::/0"),
+			bn.NonEmptyNetwork6{A: bn.Address6{}, K: 128},
+		}, {
+			bn.ParseNetwork6("2001:db8::/32"),
+			bn.NonEmptyNetwork6{A: bn.Address6{Lo: 0x20010db8}, K: 96},
+		}, {
+			bn.ParseNetwork6("::5/128"),
+			bn.NonEmptyNetwork6{A: bn.Address6{Lo: 5}, K: 0},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("[%v, %v)", tc.Input.Left, tc.Input.Right),
+			func(t *testing.T) {
+				if tc.Input.ToNonEmptyNetwork() != tc.Expected {
+					t.Fail()
+				}
+				if tc.Expected.ToNetwork() != tc.Input {
+					t.Fail()
+				}
+			})
+	}
+}
+
+func TestFootprintSize6(t *testing.T) {
+	input := []bn.Network6{
+		bn.ParseNetwork6("::/1"),
+		bn.ParseNetwork6("8000::/1"),
+	}
+	expected := bn.Address6{Top: 1}
+	if size := bn.FootprintSize(bn.IntervalSlice(input)); size != expected {
+		t.Error("expecting", expected, "got", size)
+	}
+}
@@ -89,6 +89,7 @@ func TestCanonical(t *testing.T) {
 
 	cases := []Case{
 		{"empty", []bn.Interval{}, []bn.Interval{}},
+		{"empty_interval", []bn.Interval{{1, 1}, {2, 3}}, []bn.Interval{{2, 3}}},
 		{"singleton", []bn.Interval{{1, 2}}, []bn.Interval{{1, 2}}},
 		{"reverse", []bn.Interval{{4, 5}, {1, 2}}, []bn.Interval{{1, 2}, {4, 5}}},
 		{"adjacent", []bn.Interval{{1, 2}, {2, 3}}, []bn.Interval{{1, 3}}},
@@ -149,7 +150,7 @@ func TestFootprintSize(t *testing.T) {
 	type Case struct {
 		Name     string
 		Input    []bn.Interval
-		Expected int
+		Expected bn.Address
 	}
 
 	cases := []Case{
@@ -176,3 +177,29 @@ func TestIntervalSlice(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestIntersects(t *testing.T) {
+	type Case struct {
+		Name     string
+		Input    bn.Interval
+		Expected bool
+	}
+	canonical := bn.Canonical([]bn.Interval{{5, 8}, {1, 3}, {10, 10}})
+	cases := []Case{
+		{"before", bn.Interval{0, 1}, false},
+		{"overlap_left", bn.Interval{0, 2}, true},
+		{"inside", bn.Interval{6, 7}, true},
+		{"between", bn.Interval{3, 5}, false},
+		{"spanning", bn.Interval{2, 9}, true},
+		{"dropped_empty", bn.Interval{9, 11}, false},
+		{"after", bn.Interval{8, 20}, false},
+		{"empty", bn.Interval{6, 6}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if tc.Expected != bn.Intersects(tc.Input, canonical) {
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package boundednet
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Kinds of parse failure.  Use errors.Is to test a *ParseError against
+// these.
+var (
+	ErrSyntax       = errors.New("invalid syntax")
+	ErrOctet        = errors.New("octet out of range")
+	ErrPrefixLength = errors.New("prefix length out of range")
+	ErrHostBits     = errors.New("host bits set")
+)
+
+// Error returned when parsing a network fails.
+type ParseError struct {
+	// The text which failed to parse.
+	Input string
+
+	// 1-based position of the offending text.  Line is 0 unless
+	// the input was read by ParseNetworks.  Column is relative to
+	// the start of the line.
+	Line, Column int
+
+	// One of ErrSyntax, ErrOctet, ErrPrefixLength or ErrHostBits.
+	Err error
+}
+
+func (this *ParseError) Error() string {
+	if this.Line > 0 {
+		return fmt.Sprintf("line %d, column %d: %q: %v", this.Line, this.Column, this.Input, this.Err)
+	}
+	return fmt.Sprintf("column %d: %q: %v", this.Column, this.Input, this.Err)
+}
+
+func (this *ParseError) Unwrap() error {
+	return this.Err
+}
+
+// Parser of networks in CIDR notation.  The zero value masks away host
+// bits, like ParseNetwork.
+type Parser struct {
+	// Reject networks such as 192.168.1.1/24 whose address has
+	// bits set outside of the prefix instead of masking them
+	// away.
+	RejectHostBits bool
+}
+
+// Parse an IPv4 network such as 192.168.1.0/24.
+func (this Parser) ParseNetwork(netmask string) (Network, error) {
+	addr, ones, err := splitNetmask(netmask, 32)
+	if err != nil {
+		return Network{}, err
+	}
+
+	left, err := parseAddress(netmask, addr)
+	if err != nil {
+		return Network{}, err
+	}
+
+	size := Address(1) << (32 - ones)
+	if left%size != 0 {
+		if this.RejectHostBits {
+			return Network{}, &ParseError{Input: netmask, Column: 1, Err: ErrHostBits}
+		}
+		left = left - left%size
+	}
+	return Network{left, left + size}, nil
+}
+
+// Parse an IPv6 network such as 2001:db8::/32.
+func (this Parser) ParseNetwork6(netmask string) (Network6, error) {
+	addr, ones, err := splitNetmask(netmask, 128)
+	if err != nil {
+		return Network6{}, err
+	}
+
+	ip, err := parseIP6(netmask, addr, 1)
+	if err != nil {
+		return Network6{}, err
+	}
+	prefix := netip.PrefixFrom(ip, int(ones))
+	if this.RejectHostBits && prefix.Masked().Addr() != ip {
+		return Network6{}, &ParseError{Input: netmask, Column: 1, Err: ErrHostBits}
+	}
+	left := address6(prefix.Masked().Addr())
+	size := Address6{Lo: 1}.Lsh(128 - ones)
+	return Network6{left, left.Add(size)}, nil
+}
+
+// Parse a dotted quad which starts at column 1 of input.
+func parseAddress(input, addr string) (Address, error) {
+	var result Address
+	column := 1
+	bytes := strings.Split(addr, ".")
+	if len(bytes) != 4 {
+		return 0, &ParseError{Input: input, Column: 1, Err: ErrSyntax}
+	}
+	for _, b := range bytes {
+		// Like netip.ParseAddr, reject leading zeros, which some
+		// parsers read as octal.
+		if len(b) > 1 && b[0] == '0' {
+			return 0, &ParseError{Input: input, Column: column, Err: ErrSyntax}
+		}
+		byte, err := strconv.ParseUint(b, 10, 64)
+		if err != nil {
+			if errors.Is(err, strconv.ErrRange) {
+				return 0, &ParseError{Input: input, Column: column, Err: ErrOctet}
+			}
+			return 0, &ParseError{Input: input, Column: column, Err: ErrSyntax}
+		}
+		if byte > 255 {
+			return 0, &ParseError{Input: input, Column: column, Err: ErrOctet}
+		}
+		result = result<<8 + Address(byte)
+		column += len(b) + 1
+	}
+	return result, nil
+}
+
+// Parse an IPv6 address which starts at the given column of input.
+func parseIP6(input, addr string, column int) (netip.Addr, error) {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil || !ip.Is6() || ip.Zone() != "" {
+		return netip.Addr{}, &ParseError{Input: input, Column: column, Err: ErrSyntax}
+	}
+	return ip, nil
+}
+
+// Parse an IPv4 range of addresses such as 10.0.0.5-10.0.0.200.  Both
+// ends are inclusive.  The empty string is parsed as the empty interval.
+func (this Parser) ParseInterval(text string) (Interval, error) {
+	if text == "" {
+		return Interval{}, nil
+	}
+	first, last, column, err := splitRange(text)
+	if err != nil {
+		return Interval{}, err
+	}
+	left, err := parseAddress(text, first)
+	if err != nil {
+		return Interval{}, err
+	}
+	right, err := parseAddress(text, last)
+	if err != nil {
+		err.(*ParseError).Column += column - 1
+		return Interval{}, err
+	}
+	if right < left {
+		return Interval{}, &ParseError{Input: text, Column: column, Err: ErrSyntax}
+	}
+	return Interval{left, right + 1}, nil
+}
+
+// Parse an IPv6 range of addresses such as 2001:db8::5-2001:db8::c8.
+func (this Parser) ParseInterval6(text string) (Interval6, error) {
+	if text == "" {
+		return Interval6{}, nil
+	}
+	first, last, column, err := splitRange(text)
+	if err != nil {
+		return Interval6{}, err
+	}
+	leftIP, err := parseIP6(text, first, 1)
+	if err != nil {
+		return Interval6{}, err
+	}
+	rightIP, err := parseIP6(text, last, column)
+	if err != nil {
+		return Interval6{}, err
+	}
+	left, right := address6(leftIP), address6(rightIP)
+	if right.Less(left) {
+		return Interval6{}, &ParseError{Input: text, Column: column, Err: ErrSyntax}
+	}
+	return Interval6{left, right.Add(Address6{Lo: 1})}, nil
+}
+
+// Split "first-last", also returning the column at which last starts.
+func splitRange(text string) (string, string, int, error) {
+	dash := strings.IndexByte(text, '-')
+	if dash < 0 {
+		return "", "", 0, &ParseError{Input: text, Column: len(text) + 1, Err: ErrSyntax}
+	}
+	return text[:dash], text[dash+1:], dash + 2, nil
+}
+
+// Split "addr/ones" checking that ones is at most maxOnes.
+func splitNetmask(netmask string, maxOnes uint) (string, uint, error) {
+	slash := strings.IndexByte(netmask, '/')
+	if slash < 0 {
+		return "", 0, &ParseError{Input: netmask, Column: len(netmask) + 1, Err: ErrSyntax}
+	}
+	addr, mask := netmask[:slash], netmask[slash+1:]
+	if len(mask) > 1 && mask[0] == '0' {
+		return "", 0, &ParseError{Input: netmask, Column: slash + 2, Err: ErrSyntax}
+	}
+
+	ones, err := strconv.ParseUint(mask, 10, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return "", 0, &ParseError{Input: netmask, Column: slash + 2, Err: ErrPrefixLength}
+		}
+		return "", 0, &ParseError{Input: netmask, Column: slash + 2, Err: ErrSyntax}
+	}
+	if ones > uint64(maxOnes) {
+		return "", 0, &ParseError{Input: netmask, Column: slash + 2, Err: ErrPrefixLength}
+	}
+	return addr, uint(ones), nil
+}
+
+// Read networks, one per line.  Surrounding whitespace, blank lines and
+// comments starting with # are ignored.  Parsing stops at the first
+// error.
+func (this Parser) ParseNetworks(r io.Reader) ([]Network, error) {
+	return parseLines(r, this.ParseNetwork)
+}
+
+// IPv6 version of ParseNetworks.
+func (this Parser) ParseNetworks6(r io.Reader) ([]Network6, error) {
+	return parseLines(r, this.ParseNetwork6)
+}
+
+// Read address ranges, one per line, either as inclusive ranges such as
+// 10.0.0.5-10.0.0.200 or as networks in CIDR notation.  Blank lines and
+// comments are ignored as for ParseNetworks.
+func (this Parser) ParseIntervals(r io.Reader) ([]Interval, error) {
+	return parseLines(r, func(text string) (Interval, error) {
+		if strings.IndexByte(text, '/') >= 0 {
+			network, err := this.ParseNetwork(text)
+			return Interval(network), err
+		}
+		return this.ParseInterval(text)
+	})
+}
+
+// IPv6 version of ParseIntervals.
+func (this Parser) ParseIntervals6(r io.Reader) ([]Interval6, error) {
+	return parseLines(r, func(text string) (Interval6, error) {
+		if strings.IndexByte(text, '/') >= 0 {
+			network, err := this.ParseNetwork6(text)
+			return Interval6(network), err
+		}
+		return this.ParseInterval6(text)
+	})
+}
+
+func parseLines[T any](r io.Reader, parse func(string) (T, error)) ([]T, error) {
+	result := []T{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if hash := strings.IndexByte(text, '#'); hash >= 0 {
+			text = text[:hash]
+		}
+		trimmed := strings.TrimLeft(text, " \t")
+		indent := len(text) - len(trimmed)
+		trimmed = strings.TrimRight(trimmed, " \t\r")
+		if trimmed == "" {
+			continue
+		}
+
+		network, err := parse(trimmed)
+		if err != nil {
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) {
+				parseErr.Line = line
+				parseErr.Column += indent
+			}
+			return nil, err
+		}
+		result = append(result, network)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Parse an IPv4 network, returning a *ParseError on failure.
+func ParseNetworkE(netmask string) (Network, error) {
+	return Parser{}.ParseNetwork(netmask)
+}
+
+// Parse an IPv6 network, returning a *ParseError on failure.
+func ParseNetwork6E(netmask string) (Network6, error) {
+	return Parser{}.ParseNetwork6(netmask)
+}
+
+// Parse an IPv4 range of addresses, returning a *ParseError on failure.
+func ParseIntervalE(text string) (Interval, error) {
+	return Parser{}.ParseInterval(text)
+}
+
+// Parse an IPv6 range of addresses, returning a *ParseError on failure.
+func ParseInterval6E(text string) (Interval6, error) {
+	return Parser{}.ParseInterval6(text)
+}
+
+// Read IPv4 networks, one per line.  See Parser.ParseNetworks.
+func ParseNetworks(r io.Reader) ([]Network, error) {
+	return Parser{}.ParseNetworks(r)
+}
+
+// Read IPv6 networks, one per line.  See Parser.ParseNetworks.
+func ParseNetworks6(r io.Reader) ([]Network6, error) {
+	return Parser{}.ParseNetworks6(r)
+}
+
+// Read IPv4 address ranges, one per line.  See Parser.ParseIntervals.
+func ParseIntervals(r io.Reader) ([]Interval, error) {
+	return Parser{}.ParseIntervals(r)
+}
+
+// Read IPv6 address ranges, one per line.  See Parser.ParseIntervals.
+func ParseIntervals6(r io.Reader) ([]Interval6, error) {
+	return Parser{}.ParseIntervals6(r)
+}
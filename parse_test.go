@@ -0,0 +1,126 @@
+package boundednet_test
+
+import (
+	"errors"
+	bn "github.com/fhltang/boundednet"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseNetworkE(t *testing.T) {
+	type Case struct {
+		Input          string
+		Expected       bn.Network
+		ExpectedErr    error
+		ExpectedColumn int
+	}
+	cases := []Case{
+		{"192.168.1.0/24", bn.ParseNetwork("192.168.1.0/24"), nil, 0},
+		{"192.168.1.1/24", bn.ParseNetwork("192.168.1.0/24"), nil, 0},
+		{"0.0.0.0/0", bn.Network{0, 1 << 32}, nil, 0},
+		{"192.168.1.0", bn.Network{}, bn.ErrSyntax, 12},
+		{"192.168.1/24", bn.Network{}, bn.ErrSyntax, 1},
+		{"192.168.x.0/24", bn.Network{}, bn.ErrSyntax, 9},
+		{"192.168..0/24", bn.Network{}, bn.ErrSyntax, 9},
+		{"192.168.256.0/24", bn.Network{}, bn.ErrOctet, 9},
+		{"010.0.0.010/32", bn.Network{}, bn.ErrSyntax, 1},
+		{"10.0.0.00/32", bn.Network{}, bn.ErrSyntax, 8},
+		{"10.0.0.0/8", bn.ParseNetwork("10.0.0.0/8"), nil, 0},
+		{"192.168.1.0/33", bn.Network{}, bn.ErrPrefixLength, 13},
+		{"192.168.1.0/-1", bn.Network{}, bn.ErrSyntax, 13},
+		{"192.168.1.0/", bn.Network{}, bn.ErrSyntax, 13},
+		{"10.0.0.0/024", bn.Network{}, bn.ErrSyntax, 10},
+		{"10.0.0.0/0", bn.Network{0, 1 << 32}, nil, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Input, func(t *testing.T) {
+			net, err := bn.ParseNetworkE(tc.Input)
+			if !errors.Is(err, tc.ExpectedErr) {
+				t.Fatal("Expected error", tc.ExpectedErr, "got", err)
+			}
+			if err != nil {
+				parseErr := err.(*bn.ParseError)
+				if parseErr.Column != tc.ExpectedColumn {
+					t.Error("Expected column", tc.ExpectedColumn, "got", parseErr.Column)
+				}
+				return
+			}
+			if tc.Expected != net {
+				t.Error("Expected", tc.Expected, "got", net)
+			}
+		})
+	}
+}
+
+func TestParseNetwork6E(t *testing.T) {
+	type Case struct {
+		Input       string
+		ExpectedErr error
+	}
+	cases := []Case{
+		{"2001:db8::/32", nil},
+		{"2001:db8::1/32", nil},
+		{"2001:db8::", bn.ErrSyntax},
+		{"2001:db8::g/32", bn.ErrSyntax},
+		{"2001:db8::/032", bn.ErrSyntax},
+		{"192.168.1.0/24", bn.ErrSyntax},
+		{"fe80::1%eth0/64", bn.ErrSyntax},
+		{"2001:db8::/129", bn.ErrPrefixLength},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Input, func(t *testing.T) {
+			_, err := bn.ParseNetwork6E(tc.Input)
+			if !errors.Is(err, tc.ExpectedErr) {
+				t.Error("Expected error", tc.ExpectedErr, "got", err)
+			}
+		})
+	}
+}
+
+func TestParserRejectHostBits(t *testing.T) {
+	parser := bn.Parser{RejectHostBits: true}
+	if _, err := parser.ParseNetwork("192.168.1.0/24"); err != nil {
+		t.Error("Unexpected error", err)
+	}
+	if _, err := parser.ParseNetwork("192.168.1.1/24"); !errors.Is(err, bn.ErrHostBits) {
+		t.Error("Expected ErrHostBits, got", err)
+	}
+	if _, err := parser.ParseNetwork6("2001:db8::/32"); err != nil {
+		t.Error("Unexpected error", err)
+	}
+	if _, err := parser.ParseNetwork6("2001:db8::1/32"); !errors.Is(err, bn.ErrHostBits) {
+		t.Error("Expected ErrHostBits, got", err)
+	}
+}
+
+func TestParseNetworks(t *testing.T) {
+	input := `# allow-list
+10.0.0.0/8
+
+  192.168.1.0/24  # office
+`
+	expected := []bn.Network{
+		bn.ParseNetwork("10.0.0.0/8"),
+		bn.ParseNetwork("192.168.1.0/24"),
+	}
+	networks, err := bn.ParseNetworks(strings.NewReader(input))
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if !reflect.DeepEqual(expected, networks) {
+		t.Error("Expected", expected, "got", networks)
+	}
+}
+
+func TestParseNetworks_Error(t *testing.T) {
+	input := "10.0.0.0/8\n\n  192.168.300.0/24\n"
+	_, err := bn.ParseNetworks(strings.NewReader(input))
+	var parseErr *bn.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatal("Expected *ParseError, got", err)
+	}
+	if parseErr.Line != 3 || parseErr.Column != 11 || !errors.Is(err, bn.ErrOctet) {
+		t.Error("Unexpected error", err)
+	}
+}
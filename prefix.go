@@ -0,0 +1,150 @@
+package boundednet
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+// Returned when converting between address families.
+var ErrFamily = errors.New("wrong address family")
+
+// Convert an IPv4 prefix to a Network.  The conversion is lossless so
+// prefixes with host bits set are rejected.
+func FromPrefix(prefix netip.Prefix) (Network, error) {
+	if err := checkPrefix(prefix, true); err != nil {
+		return Network{}, err
+	}
+	b := prefix.Addr().As4()
+	left := Address(b[0])<<24 | Address(b[1])<<16 | Address(b[2])<<8 | Address(b[3])
+	return Network{left, left + Address(1)<<(32-prefix.Bits())}, nil
+}
+
+// Convert an IPv6 prefix to a Network6.  The conversion is lossless so
+// prefixes with host bits set are rejected.
+func FromPrefix6(prefix netip.Prefix) (Network6, error) {
+	if err := checkPrefix(prefix, false); err != nil {
+		return Network6{}, err
+	}
+	left := address6(prefix.Addr())
+	return Network6{left, left.Add(Address6{Lo: 1}.Lsh(uint(128 - prefix.Bits())))}, nil
+}
+
+func checkPrefix(prefix netip.Prefix, is4 bool) error {
+	if !prefix.IsValid() {
+		return fmt.Errorf("invalid prefix %v: %w", prefix, ErrSyntax)
+	}
+	if prefix.Addr().Is4() != is4 || prefix.Addr().Zone() != "" {
+		return fmt.Errorf("prefix %v: %w", prefix, ErrFamily)
+	}
+	if prefix.Masked() != prefix {
+		return fmt.Errorf("prefix %v: %w", prefix, ErrHostBits)
+	}
+	return nil
+}
+
+// Convert an IPv4 net.IPNet to a Network.
+func FromIPNet(ipnet *net.IPNet) (Network, error) {
+	prefix, err := ipNetPrefix(ipnet)
+	if err != nil {
+		return Network{}, err
+	}
+	return FromPrefix(prefix)
+}
+
+// Convert an IPv6 net.IPNet to a Network6.
+func FromIPNet6(ipnet *net.IPNet) (Network6, error) {
+	prefix, err := ipNetPrefix(ipnet)
+	if err != nil {
+		return Network6{}, err
+	}
+	return FromPrefix6(prefix)
+}
+
+func ipNetPrefix(ipnet *net.IPNet) (netip.Prefix, error) {
+	ones, bits := ipnet.Mask.Size()
+	ip := ipnet.IP
+	if bits == 32 {
+		ip = ip.To4()
+	}
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok || bits == 0 || addr.BitLen() != bits {
+		return netip.Prefix{}, fmt.Errorf("invalid network %v: %w", ipnet, ErrSyntax)
+	}
+	return netip.PrefixFrom(addr, ones), nil
+}
+
+// Convert a valid non-empty network to a prefix.
+func (this NetworkOf[A]) Prefix() (netip.Prefix, error) {
+	if !this.Valid() || this.Left == this.Right {
+		return netip.Prefix{}, fmt.Errorf("network [%v, %v) is not a prefix", this.Left, this.Right)
+	}
+	n := this.ToNonEmptyNetwork()
+	bits := int(n.A.AddressBits() - n.K)
+	switch left := any(this.Left).(type) {
+	case Address:
+		return netip.PrefixFrom(netip.AddrFrom4([4]byte{
+			byte(left >> 24), byte(left >> 16), byte(left >> 8), byte(left),
+		}), bits), nil
+	case Address6:
+		return netip.PrefixFrom(left.addr(), bits), nil
+	}
+	return netip.Prefix{}, fmt.Errorf("cannot convert %T to a prefix", this.Left)
+}
+
+// Convert a valid non-empty network to a net.IPNet.
+func (this NetworkOf[A]) IPNet() (*net.IPNet, error) {
+	prefix, err := this.Prefix()
+	if err != nil {
+		return nil, err
+	}
+	addr := prefix.Addr()
+	return &net.IPNet{
+		IP:   net.IP(addr.AsSlice()),
+		Mask: net.CIDRMask(prefix.Bits(), addr.BitLen()),
+	}, nil
+}
+
+// Solve a bounded network problem given as prefixes of a single address
+// family using solve or solve6 as appropriate.  Host bits are masked
+// away.
+func SolvePrefixes(input []netip.Prefix, m int, solve Solver, solve6 Solver6) ([]netip.Prefix, error) {
+	if len(input) == 0 {
+		return []netip.Prefix{}, nil
+	}
+
+	if input[0].Addr().Is4() {
+		networks := make([]Network, 0, len(input))
+		for _, prefix := range input {
+			network, err := FromPrefix(prefix.Masked())
+			if err != nil {
+				return nil, err
+			}
+			networks = append(networks, network)
+		}
+		return toPrefixes(solve(networks, m))
+	}
+
+	networks := make([]Network6, 0, len(input))
+	for _, prefix := range input {
+		network, err := FromPrefix6(prefix.Masked())
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return toPrefixes(solve6(networks, m))
+}
+
+func toPrefixes[A Addr[A]](networks []NetworkOf[A]) ([]netip.Prefix, error) {
+	result := make([]netip.Prefix, 0, len(networks))
+	for _, network := range networks {
+		prefix, err := network.Prefix()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, prefix)
+	}
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package boundednet_test
+
+import (
+	"errors"
+	bn "github.com/fhltang/boundednet"
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func TestFromPrefix(t *testing.T) {
+	type Case struct {
+		Input       string
+		Expected    bn.Network
+		ExpectedErr error
+	}
+	cases := []Case{
+		{"192.168.1.0/24", bn.ParseNetwork("192.168.1.0/24"), nil},
+		{"0.0.0.0/0", bn.Network{0, 1 << 32}, nil},
+		{"10.0.0.1/32", bn.ParseNetwork("10.0.0.1/32"), nil},
+		{"192.168.1.1/24", bn.Network{}, bn.ErrHostBits},
+		{"2001:db8::/32", bn.Network{}, bn.ErrFamily},
+		{"::ffff:10.0.0.0/104", bn.Network{}, bn.ErrFamily},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Input, func(t *testing.T) {
+			network, err := bn.FromPrefix(netip.MustParsePrefix(tc.Input))
+			if !errors.Is(err, tc.ExpectedErr) {
+				t.Fatal("Expected error", tc.ExpectedErr, "got", err)
+			}
+			if err != nil {
+				return
+			}
+			if network != tc.Expected {
+				t.Error("Expected", tc.Expected, "got", network)
+			}
+			prefix, err := network.Prefix()
+			if err != nil || prefix.String() != tc.Input {
+				t.Error("Expected", tc.Input, "got", prefix, err)
+			}
+		})
+	}
+}
+
+func TestFromPrefix6(t *testing.T) {
+	for _, s := range []string{"::/0", "2001:db8::/32", "2001:db8::1/128"} {
+		t.Run(s, func(t *testing.T) {
+			network, err := bn.FromPrefix6(netip.MustParsePrefix(s))
+			if err != nil || network != bn.ParseNetwork6(s) {
+				t.Fatal("Expected", bn.ParseNetwork6(s), "got", network, err)
+			}
+			prefix, err := network.Prefix()
+			if err != nil || prefix.String() != s {
+				t.Error("Expected", s, "got", prefix, err)
+			}
+		})
+	}
+
+	if _, err := bn.FromPrefix6(netip.MustParsePrefix("10.0.0.0/8")); !errors.Is(err, bn.ErrFamily) {
+		t.Error("Expected ErrFamily, got", err)
+	}
+	if _, err := bn.FromPrefix6(netip.Prefix{}); !errors.Is(err, bn.ErrSyntax) {
+		t.Error("Expected ErrSyntax, got", err)
+	}
+}
+
+func TestNetworkPrefix_Invalid(t *testing.T) {
+	for _, network := range []bn.Network{bn.EmptyNetwork(), {8, 11}} {
+		if _, err := network.Prefix(); err == nil {
+			t.Error("Expected error converting", network)
+		}
+	}
+}
+
+func TestIPNet(t *testing.T) {
+	for _, s := range []string{"192.168.1.0/24", "2001:db8::/32"} {
+		t.Run(s, func(t *testing.T) {
+			_, ipnet, err := net.ParseCIDR(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var roundTrip *net.IPNet
+			if ipnet.IP.To4() != nil {
+				network, err := bn.FromIPNet(ipnet)
+				if err != nil {
+					t.Fatal("Unexpected error", err)
+				}
+				roundTrip, err = network.IPNet()
+			} else {
+				network, err := bn.FromIPNet6(ipnet)
+				if err != nil {
+					t.Fatal("Unexpected error", err)
+				}
+				roundTrip, err = network.IPNet()
+			}
+			if roundTrip.String() != s {
+				t.Error("Expected", s, "got", roundTrip)
+			}
+		})
+	}
+
+	if _, err := bn.FromIPNet(&net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.IPMask{255, 0, 255, 0}}); err == nil {
+		t.Error("Expected error for non-canonical mask")
+	}
+}
@@ -0,0 +1,56 @@
+package boundednet
+
+// Split an interval into the fewest valid networks whose union is exactly
+// the interval.  The networks are in increasing order and do not overlap.
+//
+// Each network is the largest one which starts at the end of the
+// previous network and fits in the interval.
+func Decompose[A Addr[A]](intvl IntervalOf[A]) []NetworkOf[A] {
+	result := []NetworkOf[A]{}
+	one := intvl.Left.FromUint64(1)
+	left := intvl.Left
+	for left.Less(intvl.Right) {
+		// Largest network starting at left which fits in the
+		// interval.
+		k := min(left.TrailingZeros(), left.AddressBits())
+		for intvl.Right.Less(left.Add(one.Lsh(k))) {
+			k--
+		}
+		right := left.Add(one.Lsh(k))
+		result = append(result, NetworkOf[A]{left, right})
+		left = right
+	}
+	return result
+}
+
+// Split a list of intervals into the fewest valid networks whose union is
+// exactly the union of the intervals.  This is the inverse of
+// Canonical(IntervalSlice(networks)).
+func DecomposeAll[A Addr[A]](input []IntervalOf[A]) []NetworkOf[A] {
+	result := []NetworkOf[A]{}
+	for _, intvl := range Canonical(input) {
+		result = append(result, Decompose(intvl)...)
+	}
+	return result
+}
+
+// Normalise a list of arbitrary address ranges into solver input.  The
+// ranges are merged and each is split into the fewest networks covering
+// exactly that range.
+func NormaliseIntervals[A Addr[A]](input []IntervalOf[A]) []NetworkOf[A] {
+	result := DecomposeAll(input)
+	if len(result) == 0 {
+		return result
+	}
+	return NormaliseInput(result)
+}
+
+// Solve a bounded network problem whose input is given as arbitrary
+// address ranges rather than networks.
+func SolveIntervals[A Addr[A]](input []IntervalOf[A], m int, solve SolverOf[A]) []NetworkOf[A] {
+	networks := NormaliseIntervals(input)
+	if len(networks) == 0 {
+		return networks
+	}
+	return solve(networks, m)
+}
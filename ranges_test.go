@@ -0,0 +1,195 @@
+package boundednet_test
+
+import (
+	"fmt"
+	bn "github.com/fhltang/boundednet"
+	"github.com/fhltang/boundednet/binary"
+	"github.com/fhltang/boundednet/snoc"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Fewest networks whose union is exactly [left, right), by exhaustive
+// search.
+func minDecomposition(left, right bn.Address) int {
+	best := make([]int, right-left+1)
+	for l := right; l > left; {
+		l--
+		best[l-left] = -1
+		for size := bn.Address(1); l+size <= right; size *= 2 {
+			if !(bn.Network{l, l + size}).Valid() {
+				continue
+			}
+			if n := 1 + best[l+size-left]; best[l-left] < 0 || n < best[l-left] {
+				best[l-left] = n
+			}
+		}
+	}
+	return best[0]
+}
+
+func TestDecompose_Exhaustive(t *testing.T) {
+	const max = 64
+	for left := bn.Address(0); left <= max; left++ {
+		for right := left; right <= max; right++ {
+			intvl := bn.Interval{left, right}
+			networks := bn.Decompose(intvl)
+			for i, network := range networks {
+				if !network.Valid() || network.Size() == 0 {
+					t.Fatal(intvl, "invalid network", network)
+				}
+				if i > 0 && networks[i-1].Right != network.Left {
+					t.Fatal(intvl, "networks not contiguous", networks)
+				}
+			}
+			if bn.FootprintSize(bn.IntervalSlice(networks)) != right-left {
+				t.Fatal(intvl, "wrong footprint size", networks)
+			}
+			if left < right && (!bn.Subset(bn.IntervalSlice(networks), []bn.Interval{intvl}) ||
+				!bn.Subset([]bn.Interval{intvl}, bn.IntervalSlice(networks))) {
+				t.Fatal(intvl, "wrong footprint", networks)
+			}
+			if expected := minDecomposition(left, right); len(networks) != expected {
+				t.Fatal(intvl, "expected", expected, "networks, got", networks)
+			}
+		}
+	}
+}
+
+func TestDecompose_Boundaries(t *testing.T) {
+	type Case struct {
+		Input         bn.Interval
+		ExpectedCount int
+	}
+	cases := []Case{
+		{bn.Interval{0, 1 << 32}, 1},
+		{bn.Interval{1, 1 << 32}, 32},
+		{bn.Interval{0, 1<<32 - 1}, 32},
+		{bn.Interval{1, 1<<32 - 1}, 62},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Input.String(), func(t *testing.T) {
+			networks := bn.Decompose(tc.Input)
+			if len(networks) != tc.ExpectedCount {
+				t.Error("Expected", tc.ExpectedCount, "networks, got", networks)
+			}
+			if bn.FootprintSize(bn.IntervalSlice(networks)) != tc.Input.Right-tc.Input.Left {
+				t.Error("Wrong footprint size", networks)
+			}
+		})
+	}
+}
+
+func TestDecompose6(t *testing.T) {
+	whole := bn.Interval6(bn.ParseNetwork6("::/0"))
+	one := bn.Address6{Lo: 1}
+	type Case struct {
+		Input         bn.Interval6
+		ExpectedCount int
+	}
+	cases := []Case{
+		{whole, 1},
+		{bn.Interval6{one, whole.Right}, 128},
+		{bn.Interval6{whole.Left, whole.Right.Sub(one)}, 128},
+		{bn.Interval6{one, whole.Right.Sub(one)}, 254},
+		{bn.Interval6{bn.Address6{Hi: 1, Lo: 5}, bn.Address6{Hi: 1, Lo: 201}}, 9},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("[%v, %v)", tc.Input.Left, tc.Input.Right), func(t *testing.T) {
+			networks := bn.Decompose(tc.Input)
+			if len(networks) != tc.ExpectedCount {
+				t.Error("Expected", tc.ExpectedCount, "networks, got", len(networks))
+			}
+			for _, network := range networks {
+				if !network.Valid() {
+					t.Error("Invalid network", network)
+				}
+			}
+			if bn.FootprintSize(bn.IntervalSlice(networks)) != tc.Input.Right.Sub(tc.Input.Left) {
+				t.Error("Wrong footprint size", networks)
+			}
+		})
+	}
+}
+
+func TestDecomposeAll(t *testing.T) {
+	input := []bn.Interval{{6, 9}, {1, 2}, {2, 4}, {8, 10}}
+	expected := []bn.Network{{1, 2}, {2, 4}, {6, 8}, {8, 10}}
+	networks := bn.DecomposeAll(input)
+	if !reflect.DeepEqual(expected, networks) {
+		t.Error("Expected", expected, "got", networks)
+	}
+	if !reflect.DeepEqual(bn.Canonical(input), bn.Canonical(bn.IntervalSlice(networks))) {
+		t.Error("Not the inverse of Canonical")
+	}
+}
+
+func TestNormaliseIntervals(t *testing.T) {
+	type Case struct {
+		Name     string
+		Input    []bn.Interval
+		Expected []bn.Network
+	}
+	cases := []Case{
+		{"empty", []bn.Interval{}, []bn.Network{}},
+		{"empty_interval", []bn.Interval{{5, 5}}, []bn.Network{}},
+		{"network", []bn.Interval{{8, 16}}, []bn.Network{{8, 16}}},
+		{
+			"range",
+			[]bn.Interval{{5, 201}},
+			[]bn.Network{{5, 6}, {6, 8}, {8, 16}, {16, 32}, {32, 64}, {64, 128}, {128, 192}, {192, 200}, {200, 201}},
+		},
+		{
+			"overlapping",
+			[]bn.Interval{{4, 7}, {2, 5}, {9, 10}},
+			[]bn.Network{{2, 4}, {4, 6}, {6, 7}, {9, 10}},
+		},
+		{"whole", []bn.Interval{{0, 1 << 32}}, []bn.Network{{0, 1 << 32}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			networks := bn.NormaliseIntervals(tc.Input)
+			if !reflect.DeepEqual(tc.Expected, networks) {
+				t.Error("Expected", tc.Expected, "got", networks)
+			}
+		})
+	}
+}
+
+func TestSolveIntervals(t *testing.T) {
+	input, err := bn.ParseIntervals(strings.NewReader(`
+10.0.0.5-10.0.0.200
+10.0.1.0/24
+192.168.0.1-192.168.0.2
+`))
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	expected := []bn.Network{
+		bn.ParseNetwork("10.0.0.0/23"),
+		bn.ParseNetwork("192.168.0.0/30"),
+	}
+	for name, solution := range map[string][]bn.Network{
+		"snoc":   snoc.SolveIntervals(input, 2),
+		"binary": binary.SolveIntervals(input, 2),
+	} {
+		if !reflect.DeepEqual(expected, solution) {
+			t.Error(name, "expected", expected, "got", solution)
+		}
+	}
+}
+
+func TestParseIntervals6(t *testing.T) {
+	input, err := bn.ParseIntervals6(strings.NewReader("2001:db8::5-2001:db8::c8\n2001:db8:1::/48\n"))
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	expected := []bn.Interval6{
+		{bn.Address6{Hi: 0x20010db8 << 32, Lo: 5}, bn.Address6{Hi: 0x20010db8 << 32, Lo: 201}},
+		bn.Interval6(bn.ParseNetwork6("2001:db8:1::/48")),
+	}
+	if !reflect.DeepEqual(expected, input) {
+		t.Error("Expected", expected, "got", input)
+	}
+}
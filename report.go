@@ -0,0 +1,132 @@
+package boundednet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// How one network of a solution covers the input.
+type ReportEntryOf[A Addr[A]] struct {
+	Network NetworkOf[A]
+
+	// Normalised input networks contained in Network.
+	Covers []NetworkOf[A]
+
+	// Number of addresses in Network not in any input network.
+	Excess A
+}
+
+type ReportEntry = ReportEntryOf[Address]
+type ReportEntry6 = ReportEntryOf[Address6]
+
+// Explanation of a solution in terms of the input it covers.
+type ReportOf[A Addr[A]] struct {
+	Entries []ReportEntryOf[A]
+
+	// Normalised input networks not contained in any network of the
+	// solution.  Empty for a solution returned by a solver.
+	Uncovered []NetworkOf[A]
+
+	// Number of addresses covered by the solution but not by the input.
+	Excess A
+}
+
+type Report = ReportOf[Address]
+type Report6 = ReportOf[Address6]
+
+// Explain which input networks each network of a solution covers and
+// how many extra addresses it adds.
+func Explain[A Addr[A]](input, solution []NetworkOf[A]) ReportOf[A] {
+	var normalised []NetworkOf[A]
+	for _, network := range input {
+		if network.Left != network.Right {
+			normalised = append(normalised, network)
+		}
+	}
+	if len(normalised) > 0 {
+		normalised = NormaliseInput(normalised)
+	}
+	footprint := Canonical(IntervalSlice(normalised))
+
+	report := ReportOf[A]{Entries: make([]ReportEntryOf[A], 0, len(solution))}
+	covered := make([]bool, len(normalised))
+	for _, network := range solution {
+		entry := ReportEntryOf[A]{Network: network, Excess: network.Size()}
+
+		// Normalised input networks are disjoint and sorted, so those
+		// within network are contiguous.
+		i := sort.Search(len(normalised), func(j int) bool {
+			return !normalised[j].Left.Less(network.Left)
+		})
+		for ; i < len(normalised) && !network.Right.Less(normalised[i].Right); i++ {
+			entry.Covers = append(entry.Covers, normalised[i])
+			covered[i] = true
+		}
+
+		// Subtract the part of the input footprint within network,
+		// including inputs only partly within it.
+		i = sort.Search(len(footprint), func(j int) bool {
+			return network.Left.Less(footprint[j].Right)
+		})
+		for ; i < len(footprint) && footprint[i].Left.Less(network.Right); i++ {
+			left, right := footprint[i].Left, footprint[i].Right
+			if left.Less(network.Left) {
+				left = network.Left
+			}
+			if network.Right.Less(right) {
+				right = network.Right
+			}
+			entry.Excess = entry.Excess.Sub(right.Sub(left))
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+
+	for i, network := range normalised {
+		if !covered[i] && !Subset([]IntervalOf[A]{IntervalOf[A](network)}, IntervalSlice(solution)) {
+			report.Uncovered = append(report.Uncovered, network)
+		}
+	}
+
+	// Solution networks may overlap, so the total is not the sum of
+	// the entries.
+	all := append(IntervalSlice(solution), footprint...)
+	report.Excess = FootprintSize(all).Sub(FootprintSize(footprint))
+	return report
+}
+
+// Solve a bounded network problem and explain the solution.
+func SolveReport[A Addr[A]](input []NetworkOf[A], m int, solve SolverOf[A]) ReportOf[A] {
+	return Explain(input, solve(input, m))
+}
+
+// The networks of the solution, in order.
+func (this ReportOf[A]) Solution() []NetworkOf[A] {
+	result := make([]NetworkOf[A], 0, len(this.Entries))
+	for _, entry := range this.Entries {
+		result = append(result, entry.Network)
+	}
+	return result
+}
+
+// Format the network, its excess in decimal and the networks it covers.
+func (this ReportEntryOf[A]) String() string {
+	covers := make([]string, 0, len(this.Covers))
+	for _, network := range this.Covers {
+		covers = append(covers, network.String())
+	}
+	return fmt.Sprintf("%v: excess %s, covers %s", this.Network, this.Excess.Decimal(), strings.Join(covers, " "))
+}
+
+// Format one line per network of the solution followed by the total.
+func (this ReportOf[A]) String() string {
+	var b strings.Builder
+	for _, entry := range this.Entries {
+		fmt.Fprintf(&b, "%v\n", entry)
+	}
+	for _, network := range this.Uncovered {
+		fmt.Fprintf(&b, "%v: uncovered\n", network)
+	}
+	fmt.Fprintf(&b, "total excess %s\n", this.Excess.Decimal())
+	return b.String()
+}
@@ -0,0 +1,131 @@
+package boundednet_test
+
+import (
+	"fmt"
+	bn "github.com/fhltang/boundednet"
+	"github.com/fhltang/boundednet/binary"
+	"github.com/fhltang/boundednet/snoc"
+	"reflect"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	type Case struct {
+		Name     string
+		Input    []bn.Network
+		Solution []bn.Network
+		Expected bn.Report
+	}
+	cases := []Case{
+		{
+			"exact",
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/24")},
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/24")},
+			bn.Report{
+				Entries: []bn.ReportEntry{
+					{bn.ParseNetwork("10.0.0.0/24"), []bn.Network{bn.ParseNetwork("10.0.0.0/24")}, 0},
+				},
+			},
+		},
+		{
+			"excess",
+			[]bn.Network{
+				bn.ParseNetwork("192.168.3.0/24"),
+				bn.ParseNetwork("192.168.0.0/24"),
+				bn.ParseNetwork("192.168.0.128/25"),
+				bn.ParseNetwork("10.0.0.1/32"),
+			},
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/30"), bn.ParseNetwork("192.168.0.0/22")},
+			bn.Report{
+				Entries: []bn.ReportEntry{
+					{bn.ParseNetwork("10.0.0.0/30"), []bn.Network{bn.ParseNetwork("10.0.0.1/32")}, 3},
+					{bn.ParseNetwork("192.168.0.0/22"), []bn.Network{
+						bn.ParseNetwork("192.168.0.0/24"),
+						bn.ParseNetwork("192.168.3.0/24"),
+					}, 512},
+				},
+				Excess: 515,
+			},
+		},
+		{
+			"uncovered",
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/24"), bn.ParseNetwork("10.0.2.0/24")},
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/25"), bn.ParseNetwork("10.0.2.0/24")},
+			bn.Report{
+				Entries: []bn.ReportEntry{
+					{bn.ParseNetwork("10.0.0.0/25"), nil, 0},
+					{bn.ParseNetwork("10.0.2.0/24"), []bn.Network{bn.ParseNetwork("10.0.2.0/24")}, 0},
+				},
+				Uncovered: []bn.Network{bn.ParseNetwork("10.0.0.0/24")},
+			},
+		},
+		{
+			"overlapping_solution",
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/25")},
+			[]bn.Network{bn.ParseNetwork("10.0.0.0/24"), bn.ParseNetwork("10.0.0.128/25")},
+			bn.Report{
+				Entries: []bn.ReportEntry{
+					{bn.ParseNetwork("10.0.0.0/24"), []bn.Network{bn.ParseNetwork("10.0.0.0/25")}, 128},
+					{bn.ParseNetwork("10.0.0.128/25"), nil, 128},
+				},
+				Excess: 128,
+			},
+		},
+		{
+			"empty",
+			[]bn.Network{},
+			[]bn.Network{},
+			bn.Report{Entries: []bn.ReportEntry{}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			report := bn.Explain(tc.Input, tc.Solution)
+			if !reflect.DeepEqual(tc.Expected, report) {
+				t.Error("Expected", tc.Expected, "got", report)
+			}
+		})
+	}
+}
+
+func TestReportEntryString(t *testing.T) {
+	entry := bn.ReportEntry{bn.ParseNetwork("10.0.0.0/22"), []bn.Network{bn.ParseNetwork("10.0.0.0/23")}, 512}
+	expected := "10.0.0.0/22: excess 512, covers 10.0.0.0/23"
+	if s := fmt.Sprint(entry); s != expected {
+		t.Error("Expected", expected, "got", s)
+	}
+}
+
+func TestSolveReport(t *testing.T) {
+	input := []bn.Network{
+		bn.ParseNetwork("192.168.0.0/24"),
+		bn.ParseNetwork("192.168.1.0/24"),
+		bn.ParseNetwork("192.168.3.0/24"),
+		bn.ParseNetwork("10.0.0.1/32"),
+	}
+	expected := "10.0.0.1/32: excess 0, covers 10.0.0.1/32\n" +
+		"192.168.0.0/22: excess 256, covers 192.168.0.0/24 192.168.1.0/24 192.168.3.0/24\n" +
+		"total excess 256\n"
+	for name, report := range map[string]bn.Report{
+		"snoc":   snoc.SolveReport(input, 2),
+		"binary": binary.SolveReport(input, 2),
+	} {
+		if report.String() != expected {
+			t.Error(name, "expected", expected, "got", report.String())
+		}
+		solution := report.Solution()
+		if !reflect.DeepEqual(solution, snoc.Solve(input, 2)) {
+			t.Error(name, "expected solution", snoc.Solve(input, 2), "got", solution)
+		}
+	}
+}
+
+func TestSolveReport6(t *testing.T) {
+	input := []bn.Network6{bn.ParseNetwork6("2001:db8::/33"), bn.ParseNetwork6("2001:db8:8000::/34")}
+	report := binary.SolveReport(input, 1)
+	expected := "2001:db8::/32: excess 19807040628566084398385987584, covers 2001:db8::/33 2001:db8:8000::/34\n" +
+		"total excess 19807040628566084398385987584\n"
+	if report.String() != expected {
+		t.Error("Expected", expected, "got", report.String())
+	}
+}
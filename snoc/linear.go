@@ -0,0 +1,268 @@
+package snoc
+
+import (
+	"context"
+	bn "github.com/fhltang/boundednet"
+	"sort"
+)
+
+// Marks a cell of LinearSolverOf.Choice with no feasible solution.
+const Infeasible = -1
+
+// A variant of BacktrackingSolverOf using memory O(N * M) of small ints
+// instead of O(N^2 + N * M) structs.  LeastNetwork is computed on demand
+// rather than precomputed, by widening it as each cell scans its
+// candidates, and only two rows of MinSize are kept.
+// Backtracking recomputes networks from the column choices.
+//
+// Returns the same solutions as BacktrackingSolverOf.
+type LinearSolverOf[A bn.Addr[A]] struct {
+	// Inputs.
+	Input []bn.NetworkOf[A]
+	M     int
+
+	// Intervals which output networks must not intersect.
+	// Canonicalised by Init.
+	Forbidden []bn.IntervalOf[A]
+
+	// Cost which is minimised.  Defaults to bn.SizeCost if nil.
+	Cost bn.CostOf[A]
+
+	// Checked for cancellation while computing rows.  Nil means never
+	// cancelled.
+	Context context.Context
+
+	// Consider only the least n of each run of n with the same
+	// LeastNetwork(n+1, k+1), of which there are O(address bits),
+	// instead of every n <= k.  Since MinSize is monotonic in n, the
+	// other n in a run cannot do better.
+	Monotone bool
+
+	// Choice[m][k] is the column n such that a minimal solution for
+	// cell (m, k) extends one for cell (m-1, n) with
+	// LeastNetwork(n+1, k+1), or Infeasible.  Choice[0][k] is 0 unless
+	// infeasible.
+	Choice [][]int32
+
+	// MinSize of the last row computed.
+	MinSize []A
+}
+
+type LinearSolver = LinearSolverOf[bn.Address]
+type LinearSolver6 = LinearSolverOf[bn.Address6]
+
+// Solve a bounded network problem.  Returns nil if there is no feasible
+// solution or the solver's Context is cancelled.
+func (this *LinearSolverOf[A]) Solve(input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+	this.Init(input, m)
+	if len(this.Input) == 0 {
+		return []bn.NetworkOf[A]{}
+	}
+	if err := this.ComputeTable(); err != nil {
+		return nil
+	}
+	return this.Backtrack(this.M, len(this.Input))
+}
+
+// Solve a bounded network problem after validating it, stopping early if
+// ctx is cancelled.
+func (this *LinearSolverOf[A]) SolveContext(ctx context.Context, input []bn.NetworkOf[A], m int) ([]bn.NetworkOf[A], error) {
+	if err := bn.ValidateProblem(input, m); err != nil {
+		return nil, err
+	}
+	if len(input) == 0 {
+		return []bn.NetworkOf[A]{}, nil
+	}
+
+	this.Context = ctx
+	this.Init(input, m)
+	if err := this.ComputeTable(); err != nil {
+		return nil, err
+	}
+	solution := this.Backtrack(this.M, len(this.Input))
+	if solution == nil {
+		return nil, bn.ErrInfeasible
+	}
+	return solution, nil
+}
+
+func (this *LinearSolverOf[A]) Init(input []bn.NetworkOf[A], m int) {
+	this.Input = bn.NormaliseInput(input)
+	this.M = m
+	this.Forbidden = bn.Canonical(this.Forbidden)
+	if this.Cost == nil {
+		this.Cost = bn.SizeCost[A]
+	}
+}
+
+func (this *LinearSolverOf[A]) LeastNetwork(i, j int) bn.NetworkOf[A] {
+	return bn.LeastNetwork(this.Input, i, j)
+}
+
+// Determine if a network avoids the forbidden intervals.
+func (this *LinearSolverOf[A]) Allowed(network bn.NetworkOf[A]) bool {
+	return !bn.Intersects(bn.IntervalOf[A](network), this.Forbidden)
+}
+
+// Compute Choice for every row and MinSize for the last.  Returns the
+// error of the solver's Context if it is cancelled.
+func (this *LinearSolverOf[A]) ComputeTable() error {
+	n := len(this.Input)
+	this.Choice = make([][]int32, this.M)
+	prev, cur := make([]A, n), make([]A, n)
+	for m := 0; m < this.M; m++ {
+		this.Choice[m] = make([]int32, n)
+		for k := 0; k < n; k++ {
+			if this.Context != nil {
+				if err := this.Context.Err(); err != nil {
+					return err
+				}
+			}
+			cur[k], this.Choice[m][k] = this.computeCell(m, k, prev)
+		}
+		prev, cur = cur, prev
+	}
+	this.MinSize = prev
+	return nil
+}
+
+// Compute MinSize and Choice of cell (m, k) given MinSize of row m-1.
+func (this *LinearSolverOf[A]) computeCell(m, k int, prev []A) (A, int32) {
+	if m == 0 {
+		network := this.LeastNetwork(0, k+1)
+		if !this.Allowed(network) {
+			return network.Size(), Infeasible
+		}
+		return this.Cost(network), 0
+	}
+
+	if this.Monotone {
+		return this.computeCellMonotone(m, k, prev)
+	}
+
+	// Scan n downwards so that LeastNetwork(n+1, k+1) can be widened
+	// from the previous one instead of computed afresh.  Ties go to
+	// the least n, as in BacktrackingSolverOf.
+	var minSize A
+	var network bn.NetworkOf[A]
+	choice := int32(Infeasible)
+	for n := k; n >= 0; n-- {
+		if n < k {
+			network = widen(network, this.Input[n+1])
+		}
+		if this.Choice[m-1][n] == Infeasible || !this.Allowed(network) {
+			continue
+		}
+		size := this.Cost(network).Add(prev[n])
+		if choice == Infeasible || !minSize.Less(size) {
+			minSize, choice = size, int32(n)
+		}
+	}
+	return minSize, choice
+}
+
+// Like computeCell, but for each network LeastNetwork(n+1, k+1) only
+// tries the least n giving that network.
+func (this *LinearSolverOf[A]) computeCellMonotone(m, k int, prev []A) (A, int32) {
+	var minSize A
+	var network bn.NetworkOf[A]
+	var parent bn.NonEmptyNetworkOf[A]
+	choice := int32(Infeasible)
+	for n := k; n >= 0; n-- {
+		if n == k-1 {
+			parent = this.Input[k].ToNonEmptyNetwork()
+			network = this.Input[k]
+		}
+		if n < k {
+			x := this.Input[n+1]
+			for x.Left.Less(network.Left) || network.Right.Less(x.Right) {
+				parent = bn.NonEmptyNetworkOf[A]{A: parent.A.Rsh(1), K: parent.K + 1}
+				network = parent.ToNetwork()
+			}
+
+			// Inputs are sorted and disjoint, so those within
+			// network are contiguous and end at k.  The least n is
+			// just before the first of them.
+			first := sort.Search(n+1, func(i int) bool {
+				return !this.Input[i].Left.Less(network.Left)
+			})
+			if first > 0 {
+				n = first - 1
+			} else {
+				n = 0
+			}
+		}
+
+		// Cells infeasible for n are also infeasible for every
+		// greater n.
+		if this.Choice[m-1][n] == Infeasible || !this.Allowed(network) {
+			continue
+		}
+		size := this.Cost(network).Add(prev[n])
+		if choice == Infeasible || !minSize.Less(size) {
+			minSize, choice = size, int32(n)
+		}
+	}
+	return minSize, choice
+}
+
+// The least network containing both network and x, which are valid.
+func widen[A bn.Addr[A]](network, x bn.NetworkOf[A]) bn.NetworkOf[A] {
+	if network.Left == network.Right {
+		return x
+	}
+	contains := func(n bn.NetworkOf[A]) bool {
+		return !x.Left.Less(n.Left) && !n.Right.Less(x.Right)
+	}
+	if contains(network) {
+		return network
+	}
+	parent := network.ToNonEmptyNetwork()
+	for !contains(network) {
+		parent = bn.NonEmptyNetworkOf[A]{A: parent.A.Rsh(1), K: parent.K + 1}
+		network = parent.ToNetwork()
+	}
+	return network
+}
+
+// Recover a minimal solution with at most m networks covering the first n
+// input networks.  Returns nil if there is no solution avoiding the
+// forbidden intervals.
+func (this *LinearSolverOf[A]) Backtrack(m, n int) []bn.NetworkOf[A] {
+	if this.Choice[m-1][n-1] == Infeasible {
+		return nil
+	}
+
+	output := make([]bn.NetworkOf[A], m)
+	head := m
+	for row, col := m-1, n-1; ; row-- {
+		var network bn.NetworkOf[A]
+		next := int(this.Choice[row][col])
+		if row == 0 {
+			network = this.LeastNetwork(0, col+1)
+		} else {
+			network = this.LeastNetwork(next+1, col+1)
+		}
+		if network.Left != network.Right {
+			head--
+			output[head] = network
+		}
+		if row == 0 {
+			break
+		}
+		col = next
+	}
+	return output[head:]
+}
+
+// Solve a bounded network problem using memory linear in N for each M.
+func SolveLinear[A bn.Addr[A]](input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+	solver := LinearSolverOf[A]{}
+	return solver.Solve(input, m)
+}
+
+// Solve a bounded network problem using LinearSolverOf with Monotone set.
+func SolveMonotone[A bn.Addr[A]](input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+	solver := LinearSolverOf[A]{Monotone: true}
+	return solver.Solve(input, m)
+}
@@ -3,12 +3,15 @@
 package snoc
 
 import (
+	"context"
 	bn "github.com/fhltang/boundednet"
+	"net/netip"
 )
 
-type TableCell struct {
-	// Size of a minimal solution.
-	MinSize int
+type TableCellOf[A bn.Addr[A]] struct {
+	// Cost of a minimal solution.  This is its footprint size
+	// unless the solver has a Cost.
+	MinSize A
 
 	// A minimal solution is obtained by combining Network with a
 	// subsolution (NextRow, NextCol).
@@ -16,73 +19,210 @@ type TableCell struct {
 	// Used by backtracking algorithm.
 	NextRow int
 	NextCol int
-	Network bn.Network
+	Network bn.NetworkOf[A]
+
+	// Set if no presolution avoids the forbidden intervals.
+	Infeasible bool
 }
 
-type BacktrackingSolver struct {
+type TableCell = TableCellOf[bn.Address]
+
+type BacktrackingSolverOf[A bn.Addr[A]] struct {
 	// Inputs.
-	Input []bn.Network
+	Input []bn.NetworkOf[A]
 	M     int
 
+	// Intervals which output networks must not intersect.
+	// Canonicalised by Init.
+	Forbidden []bn.IntervalOf[A]
+
+	// Cost which is minimised.  Defaults to bn.SizeCost if nil.
+	Cost bn.CostOf[A]
+
+	// Limits on the prefix length of output networks, or 0 for no
+	// limit.  Init widens or splits input networks outside them, and
+	// shorter networks are not allowed.
+	MinPrefixLength, MaxPrefixLength int
+
+	// Checked for cancellation while computing tables.  Nil means
+	// never cancelled.
+	Context context.Context
+
 	// Precomputed values of LeastNetwork().
-	leastNetwork [][]bn.Network
+	leastNetwork [][]bn.NetworkOf[A]
 
 	// Table used in dynamic programming solution.
-	Table [][]TableCell
+	Table [][]TableCellOf[A]
 }
 
-func (this *BacktrackingSolver) Solve(input []bn.Network, m int) []bn.Network {
+type BacktrackingSolver = BacktrackingSolverOf[bn.Address]
+type BacktrackingSolver6 = BacktrackingSolverOf[bn.Address6]
+
+// Solve a bounded network problem.  Returns nil if there is no feasible
+// solution or the solver's Context is cancelled.
+func (this *BacktrackingSolverOf[A]) Solve(input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+	if bn.ValidatePrefixLengths(input, this.MinPrefixLength, this.MaxPrefixLength, m) != nil {
+		return nil
+	}
 	this.Init(input, m)
-	this.PrecomputeLeastNetwork()
-	this.ComputeTable()
+	if err := this.PrecomputeLeastNetwork(); err != nil {
+		return nil
+	}
+	if err := this.ComputeTable(); err != nil {
+		return nil
+	}
 	return this.Backtrack(this.M, len(this.Input))
 }
 
-func (this *BacktrackingSolver) Init(input []bn.Network, m int) {
+// Solve a bounded network problem after validating it, stopping early if
+// ctx is cancelled.
+func (this *BacktrackingSolverOf[A]) SolveContext(ctx context.Context, input []bn.NetworkOf[A], m int) ([]bn.NetworkOf[A], error) {
+	if err := bn.ValidateProblem(input, m); err != nil {
+		return nil, err
+	}
+	if err := bn.ValidatePrefixLengths(input, this.MinPrefixLength, this.MaxPrefixLength, m); err != nil {
+		return nil, err
+	}
+	if len(input) == 0 {
+		return []bn.NetworkOf[A]{}, nil
+	}
+
+	this.Context = ctx
+	this.Init(input, m)
+	if err := this.PrecomputeLeastNetwork(); err != nil {
+		return nil, err
+	}
+	if err := this.ComputeTable(); err != nil {
+		return nil, err
+	}
+	solution := this.Backtrack(this.M, len(this.Input))
+	if solution == nil {
+		return nil, bn.ErrInfeasible
+	}
+	return solution, nil
+}
+
+func (this *BacktrackingSolverOf[A]) Init(input []bn.NetworkOf[A], m int) {
+	if this.MinPrefixLength != 0 || this.MaxPrefixLength != 0 {
+		input = bn.LimitPrefixLengths(input, this.MinPrefixLength, this.MaxPrefixLength)
+	}
 	this.Input = bn.NormaliseInput(input)
 	this.M = m
+	this.Forbidden = bn.Canonical(this.Forbidden)
+	if this.Cost == nil {
+		this.Cost = bn.SizeCost[A]
+	}
 }
 
-func (this *BacktrackingSolver) LeastNetwork(i, j int) bn.Network {
+func (this *BacktrackingSolverOf[A]) LeastNetwork(i, j int) bn.NetworkOf[A] {
 	return this.leastNetwork[j][i]
 }
 
-func (this *BacktrackingSolver) PrecomputeLeastNetwork() {
-	this.leastNetwork = make([][]bn.Network, 0, len(this.Input)+1)
+// Determine if a network avoids the forbidden intervals and is not
+// shorter than MinPrefixLength.
+func (this *BacktrackingSolverOf[A]) Allowed(network bn.NetworkOf[A]) bool {
+	if this.MinPrefixLength != 0 && network.PrefixLength() < this.MinPrefixLength {
+		return false
+	}
+	return !bn.Intersects(bn.IntervalOf[A](network), this.Forbidden)
+}
+
+// Returns the error of the solver's Context, if any.
+func (this *BacktrackingSolverOf[A]) contextErr() error {
+	if this.Context == nil {
+		return nil
+	}
+	return this.Context.Err()
+}
+
+func (this *BacktrackingSolverOf[A]) PrecomputeLeastNetwork() error {
+	this.leastNetwork = make([][]bn.NetworkOf[A], 0, len(this.Input)+1)
 	for j := 0; j <= len(this.Input); j++ {
+		if err := this.contextErr(); err != nil {
+			return err
+		}
 		this.leastNetwork = append(
-			this.leastNetwork, make([]bn.Network, j+1))
+			this.leastNetwork, make([]bn.NetworkOf[A], j+1))
 		for i := 0; i <= j; i++ {
 			this.leastNetwork[j][i] = bn.LeastNetwork(this.Input, i, j)
 		}
 	}
+	return nil
+}
+
+// Solve for the fewest networks whose cost exceeds the cost of the input
+// footprint by at most budget.  Rows of the table are computed until one
+// attains the budget.  Returns the solution and its number of networks,
+// which is 0 for empty input.
+func (this *BacktrackingSolverOf[A]) SolveBudget(input []bn.NetworkOf[A], budget A) ([]bn.NetworkOf[A], int, error) {
+	this.Init(input, 0)
+	if err := this.PrecomputeLeastNetwork(); err != nil {
+		return nil, 0, err
+	}
+	this.Table = nil
+
+	n := len(this.Input)
+	if n == 0 {
+		return []bn.NetworkOf[A]{}, 0, nil
+	}
+	inputCost := bn.FootprintCost(bn.IntervalSlice(this.Input), this.Cost)
+	for m := 1; m <= n; m++ {
+		this.M = m
+		if err := this.appendRow(); err != nil {
+			return nil, 0, err
+		}
+		cell := this.Table[m-1][n-1]
+		if !cell.Infeasible && !budget.Less(cell.MinSize.Sub(inputCost)) {
+			return this.Backtrack(m, n), m, nil
+		}
+	}
+	return nil, 0, bn.ErrInfeasible
 }
 
-func (this *BacktrackingSolver) ComputeTable() {
-	this.Table = make([][]TableCell, this.M)
+// Compute the table.  Returns the error of the solver's Context if it is
+// cancelled, leaving the table incomplete.
+func (this *BacktrackingSolverOf[A]) ComputeTable() error {
+	this.Table = make([][]TableCellOf[A], 0, this.M)
 	for m := 0; m < this.M; m++ {
-		this.Table[m] = make([]TableCell, len(this.Input))
-		for k := 0; k < len(this.Input); k++ {
-			this.Table[m][k] = this.computeCell(m, k)
+		if err := this.appendRow(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compute the next row of the table.
+func (this *BacktrackingSolverOf[A]) appendRow() error {
+	m := len(this.Table)
+	this.Table = append(this.Table, make([]TableCellOf[A], len(this.Input)))
+	for k := 0; k < len(this.Input); k++ {
+		if err := this.contextErr(); err != nil {
+			return err
 		}
+		this.Table[m][k] = this.computeCell(m, k)
 	}
+	return nil
 }
 
-func (this BacktrackingSolver) computeCell(m, k int) TableCell {
+func (this *BacktrackingSolverOf[A]) computeCell(m, k int) TableCellOf[A] {
 	if m == 0 {
 		network := this.LeastNetwork(0, k+1)
-		return TableCell{
-			MinSize: network.Size(),
-			Network: network,
+		return TableCellOf[A]{
+			MinSize:    this.Cost(network),
+			Network:    network,
+			Infeasible: !this.Allowed(network),
 		}
 	}
 
-	minimalSolution := TableCell{MinSize: 1 << 32} // max
+	minimalSolution := TableCellOf[A]{Infeasible: true}
 	for n := 0; n <= k; n++ {
 		network := this.LeastNetwork(n+1, k+1)
-		presolutionSize := network.Size() + this.Table[m-1][n].MinSize
-		if presolutionSize < minimalSolution.MinSize {
-			minimalSolution = TableCell{
+		if this.Table[m-1][n].Infeasible || !this.Allowed(network) {
+			continue
+		}
+		presolutionSize := this.Cost(network).Add(this.Table[m-1][n].MinSize)
+		if minimalSolution.Infeasible || presolutionSize.Less(minimalSolution.MinSize) {
+			minimalSolution = TableCellOf[A]{
 				MinSize: presolutionSize,
 				Network: network,
 				NextRow: m - 1,
@@ -93,13 +233,20 @@ func (this BacktrackingSolver) computeCell(m, k int) TableCell {
 	return minimalSolution
 }
 
-func (this *BacktrackingSolver) Backtrack(m, n int) []bn.Network {
-	output := make([]bn.Network, m)
+// Recover a minimal solution with at most m networks covering the first n
+// input networks.  Returns nil if there is no solution avoiding the
+// forbidden intervals.
+func (this *BacktrackingSolverOf[A]) Backtrack(m, n int) []bn.NetworkOf[A] {
+	if this.Table[m-1][n-1].Infeasible {
+		return nil
+	}
+
+	output := make([]bn.NetworkOf[A], m)
 	head := m
 	row, col := m-1, n-1
 	for {
 		cell := this.Table[row][col]
-		if cell.Network.Size() > 0 {
+		if cell.Network.Left != cell.Network.Right {
 			head--
 			output[head] = cell.Network
 		}
@@ -111,7 +258,106 @@ func (this *BacktrackingSolver) Backtrack(m, n int) []bn.Network {
 	return output[head:]
 }
 
-func Solve(input []bn.Network, m int) []bn.Network {
-	solver := BacktrackingSolver{}
+// Minimal solutions for every bound from 1 to M, read from the last
+// column of the table.  Bounds with no feasible solution are omitted, and
+// there are no points for empty input.  Must be called after ComputeTable.
+func (this *BacktrackingSolverOf[A]) Frontier() []bn.FrontierPointOf[A] {
+	n := len(this.Input)
+	result := make([]bn.FrontierPointOf[A], 0, this.M)
+	if n == 0 {
+		return result
+	}
+	for m := 1; m <= this.M; m++ {
+		cell := this.Table[m-1][n-1]
+		if cell.Infeasible {
+			continue
+		}
+		result = append(result, bn.FrontierPointOf[A]{
+			M:        m,
+			MinSize:  cell.MinSize,
+			Solution: this.Backtrack(m, n),
+		})
+	}
+	return result
+}
+
+func Solve[A bn.Addr[A]](input []bn.NetworkOf[A], m int) []bn.NetworkOf[A] {
+	solver := BacktrackingSolverOf[A]{}
+	return solver.Solve(input, m)
+}
+
+// Solve a bounded network problem whose output must avoid the forbidden
+// intervals.
+func SolveForbidden[A bn.Addr[A]](input []bn.NetworkOf[A], forbidden []bn.IntervalOf[A], m int) ([]bn.NetworkOf[A], error) {
+	solver := BacktrackingSolverOf[A]{Forbidden: forbidden}
+	solution := solver.Solve(input, m)
+	if solution == nil {
+		return nil, bn.ErrInfeasible
+	}
+	return solution, nil
+}
+
+// Solve a bounded network problem minimising the total cost of the output
+// networks instead of their footprint size.
+func SolveWeighted[A bn.Addr[A]](input []bn.NetworkOf[A], m int, cost bn.CostOf[A]) []bn.NetworkOf[A] {
+	solver := BacktrackingSolverOf[A]{Cost: cost}
 	return solver.Solve(input, m)
 }
+
+// Solve for the fewest networks whose footprint exceeds that of the input
+// by at most budget addresses.
+func SolveBudget[A bn.Addr[A]](input []bn.NetworkOf[A], budget A) ([]bn.NetworkOf[A], int, error) {
+	solver := BacktrackingSolverOf[A]{}
+	return solver.SolveBudget(input, budget)
+}
+
+// Minimal solutions of a bounded network problem for every bound from 1
+// to m, or none if m <= 0.
+func SolveFrontier[A bn.Addr[A]](input []bn.NetworkOf[A], m int) []bn.FrontierPointOf[A] {
+	if m <= 0 {
+		return []bn.FrontierPointOf[A]{}
+	}
+	solver := BacktrackingSolverOf[A]{}
+	solver.Init(input, m)
+	solver.PrecomputeLeastNetwork()
+	solver.ComputeTable()
+	return solver.Frontier()
+}
+
+// Solve a bounded network problem whose output networks have prefix
+// lengths between minLength and maxLength, where 0 means no limit.
+func SolvePrefixLengths[A bn.Addr[A]](input []bn.NetworkOf[A], m, minLength, maxLength int) ([]bn.NetworkOf[A], error) {
+	solver := BacktrackingSolverOf[A]{MinPrefixLength: minLength, MaxPrefixLength: maxLength}
+	return solver.SolveContext(context.Background(), input, m)
+}
+
+// Solve a bounded network problem after validating it, stopping early if
+// ctx is cancelled.
+func SolveContext[A bn.Addr[A]](ctx context.Context, input []bn.NetworkOf[A], m int) ([]bn.NetworkOf[A], error) {
+	solver := BacktrackingSolverOf[A]{}
+	return solver.SolveContext(ctx, input, m)
+}
+
+// Solve a bounded network problem given as IPv4 or IPv6 prefixes.  All
+// prefixes must belong to the same address family.
+func SolvePrefixes(input []netip.Prefix, m int) ([]netip.Prefix, error) {
+	return bn.SolvePrefixes(input, m, Solve[bn.Address], Solve[bn.Address6])
+}
+
+// Solve a bounded network problem whose input is given as arbitrary
+// address ranges.
+func SolveIntervals[A bn.Addr[A]](input []bn.IntervalOf[A], m int) []bn.NetworkOf[A] {
+	return bn.SolveIntervals(input, m, Solve[A])
+}
+
+// Solve a bounded network problem and explain which input networks
+// each network of the solution covers.
+func SolveReport[A bn.Addr[A]](input []bn.NetworkOf[A], m int) bn.ReportOf[A] {
+	return bn.SolveReport(input, m, Solve[A])
+}
+
+// Solve a bounded network problem for each group, with at most m networks
+// in total.
+func SolveGroups[A bn.Addr[A]](groups []bn.GroupOf[A], m int) ([]bn.FrontierPointOf[A], error) {
+	return bn.SolveGroups(groups, m, SolveFrontier[A])
+}
@@ -1,9 +1,12 @@
 package snoc_test
 
 import (
+	"errors"
 	"fmt"
 	bn "github.com/fhltang/boundednet"
 	"github.com/fhltang/boundednet/snoc"
+	"math/rand"
+	"net/netip"
 	"reflect"
 	"testing"
 )
@@ -62,7 +65,7 @@ func TestComputeTable(t *testing.T) {
 	type Case struct {
 		N               int
 		M               int
-		ExpectedMinSize int
+		ExpectedMinSize bn.Address
 	}
 
 	cases := []Case{
@@ -128,3 +131,128 @@ func TestBacktrack(t *testing.T) {
 
 	}
 }
+
+func TestSolvePrefixes(t *testing.T) {
+	input := []netip.Prefix{
+		netip.MustParsePrefix("192.168.0.0/24"),
+		netip.MustParsePrefix("192.168.1.0/24"),
+		netip.MustParsePrefix("192.168.3.0/24"),
+	}
+	expected := []netip.Prefix{
+		netip.MustParsePrefix("192.168.0.0/23"),
+		netip.MustParsePrefix("192.168.3.0/24"),
+	}
+	solution, err := snoc.SolvePrefixes(input, 2)
+	if err != nil || !reflect.DeepEqual(expected, solution) {
+		t.Error("Expected", expected, "got", solution, err)
+	}
+
+	mixed := append(input, netip.MustParsePrefix("2001:db8::/32"))
+	if _, err := snoc.SolvePrefixes(mixed, 2); !errors.Is(err, bn.ErrFamily) {
+		t.Error("Expected ErrFamily, got", err)
+	}
+}
+
+func TestSolveBudget_Forbidden(t *testing.T) {
+	input := []bn.Network{
+		bn.Network{0, 1},
+		bn.Network{1, 2},
+		bn.Network{32, 36},
+		bn.Network{60, 64},
+	}
+
+	solver := snoc.BacktrackingSolver{Forbidden: []bn.Interval{{40, 41}}}
+	solution, m, err := solver.SolveBudget(input, 1000)
+	expected := []bn.Network{bn.Network{0, 2}, bn.Network{32, 36}, bn.Network{60, 64}}
+	if err != nil || m != 3 || !reflect.DeepEqual(expected, solution) {
+		t.Error("Expected", expected, "got", solution, m, err)
+	}
+
+	solver = snoc.BacktrackingSolver{Forbidden: []bn.Interval{{33, 34}}}
+	if _, _, err := solver.SolveBudget(input, 1000); err != bn.ErrInfeasible {
+		t.Error("Expected ErrInfeasible, got", err)
+	}
+}
+
+func TestLinearSolver(t *testing.T) {
+	input := []bn.Network{
+		bn.Network{0, 1},
+		bn.Network{1, 2},
+		bn.Network{32, 36},
+		bn.Network{60, 64},
+		bn.Network{200, 201},
+	}
+	cost, err := bn.WeightedCost(1, []bn.Weight{{bn.Interval{4, 32}, 10}})
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	type Case struct {
+		Name      string
+		Forbidden []bn.Interval
+		Cost      bn.Cost
+	}
+	cases := []Case{
+		{"plain", nil, nil},
+		{"forbidden", []bn.Interval{{40, 41}}, nil},
+		{"infeasible", []bn.Interval{{33, 34}}, nil},
+		{"weighted", nil, cost},
+	}
+	for _, tc := range cases {
+		for m := 1; m <= len(input)+1; m++ {
+			t.Run(fmt.Sprintf("%s M=%d", tc.Name, m), func(t *testing.T) {
+				reference := snoc.BacktrackingSolver{Forbidden: tc.Forbidden, Cost: tc.Cost}
+				expected := reference.Solve(input, m)
+				for _, monotone := range []bool{false, true} {
+					linear := snoc.LinearSolver{Forbidden: tc.Forbidden, Cost: tc.Cost, Monotone: monotone}
+					solution := linear.Solve(input, m)
+					if !reflect.DeepEqual(expected, solution) {
+						t.Error("Monotone", monotone, "expected", expected, "got", solution)
+					}
+					if expected != nil && linear.MinSize[len(input)-1] != reference.Table[m-1][len(input)-1].MinSize {
+						t.Error("Monotone", monotone, "expected MinSize", reference.Table[m-1][len(input)-1].MinSize,
+							"got", linear.MinSize[len(input)-1])
+					}
+				}
+			})
+		}
+	}
+}
+
+// The monotone solver skips split points, so check on random inputs that
+// it computes the same last row and solution as BacktrackingSolver.
+func TestLinearSolver_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 500; trial++ {
+		input := make([]bn.Network, 1+r.Intn(60))
+		for i := range input {
+			k := uint(r.Intn(8))
+			a := bn.Address(r.Intn(1<<12)) >> k << k
+			input[i] = bn.Network{a, a + 1<<k}
+		}
+		var forbidden []bn.Interval
+		if r.Intn(2) == 0 {
+			a := bn.Address(r.Intn(1 << 12))
+			forbidden = []bn.Interval{{a, a + 1}}
+		}
+		m := 1 + r.Intn(8)
+
+		reference := snoc.BacktrackingSolver{Forbidden: forbidden}
+		expected := reference.Solve(input, m)
+		for _, monotone := range []bool{false, true} {
+			linear := snoc.LinearSolver{Forbidden: forbidden, Monotone: monotone}
+			solution := linear.Solve(input, m)
+			if !reflect.DeepEqual(expected, solution) {
+				t.Fatal("Monotone", monotone, "input", input, "forbidden", forbidden, "M =", m,
+					"expected", expected, "got", solution)
+			}
+			for k, cell := range reference.Table[m-1] {
+				infeasible := linear.Choice[m-1][k] == snoc.Infeasible
+				if infeasible != cell.Infeasible || (!infeasible && linear.MinSize[k] != cell.MinSize) {
+					t.Fatal("Monotone", monotone, "input", input, "forbidden", forbidden, "M =", m,
+						"column", k, "expected", cell, "got", linear.MinSize[k], linear.Choice[m-1][k])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package boundednet
+
+import (
+	"fmt"
+)
+
+// Format a valid network in CIDR notation, e.g. 192.168.1.0/24.  The
+// empty network is formatted as the empty string and other invalid
+// networks as closed-open intervals.
+func (this NetworkOf[A]) String() string {
+	if this.Left == this.Right {
+		return ""
+	}
+	if !this.Valid() {
+		return fmt.Sprintf("[%v, %v)", this.Left, this.Right)
+	}
+	return this.ToNonEmptyNetwork().String()
+}
+
+func (this NetworkOf[A]) MarshalText() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("cannot marshal invalid network [%v, %v)", this.Left, this.Right)
+	}
+	return []byte(this.String()), nil
+}
+
+// Parse CIDR notation; the empty string is parsed as the empty network.
+func (this *NetworkOf[A]) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*this = NetworkOf[A]{}
+		return nil
+	}
+
+	var err error
+	switch p := any(this).(type) {
+	case *Network:
+		*p, err = ParseNetworkE(string(text))
+	case *Network6:
+		*p, err = ParseNetwork6E(string(text))
+	default:
+		err = fmt.Errorf("cannot parse networks of %T", this)
+	}
+	return err
+}
+
+// Format in CIDR notation, e.g. 192.168.1.0/24.
+func (this NonEmptyNetworkOf[A]) String() string {
+	return fmt.Sprintf("%v/%d", this.A.Lsh(this.K), this.A.AddressBits()-this.K)
+}
+
+func (this NonEmptyNetworkOf[A]) MarshalText() ([]byte, error) {
+	return []byte(this.String()), nil
+}
+
+func (this *NonEmptyNetworkOf[A]) UnmarshalText(text []byte) error {
+	var network NetworkOf[A]
+	if err := network.UnmarshalText(text); err != nil {
+		return err
+	}
+	if network.Left == network.Right {
+		return fmt.Errorf("cannot parse empty network")
+	}
+	*this = network.ToNonEmptyNetwork()
+	return nil
+}
+
+// Format as an inclusive range of addresses, e.g. 10.0.0.5-10.0.0.200.
+// The empty interval is formatted as the empty string.
+func (this IntervalOf[A]) String() string {
+	if !this.Left.Less(this.Right) {
+		return ""
+	}
+	return fmt.Sprintf("%v-%v", this.Left, this.Right.Sub(this.Left.FromUint64(1)))
+}
+
+func (this IntervalOf[A]) MarshalText() ([]byte, error) {
+	if this.Right.Less(this.Left) {
+		return nil, fmt.Errorf("cannot marshal invalid interval [%v, %v)", this.Left, this.Right)
+	}
+	return []byte(this.String()), nil
+}
+
+// Parse an inclusive range of addresses; the empty string is parsed as
+// the empty interval.
+func (this *IntervalOf[A]) UnmarshalText(text []byte) error {
+	var err error
+	switch p := any(this).(type) {
+	case *Interval:
+		*p, err = ParseIntervalE(string(text))
+	case *Interval6:
+		*p, err = ParseInterval6E(string(text))
+	default:
+		err = fmt.Errorf("cannot parse intervals of %T", this)
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package boundednet_test
+
+import (
+	"encoding/json"
+	bn "github.com/fhltang/boundednet"
+	"reflect"
+	"testing"
+)
+
+func TestNetworkString(t *testing.T) {
+	type Case struct {
+		Input    bn.Network
+		Expected string
+	}
+	cases := []Case{
+		{bn.ParseNetwork("192.168.1.0/24"), "192.168.1.0/24"},
+		{bn.ParseNetwork("10.0.0.1/32"), "10.0.0.1/32"},
+		{bn.Network{0, 1 << 32}, "0.0.0.0/0"},
+		{bn.EmptyNetwork(), ""},
+		{bn.Network{8, 11}, "[0.0.0.8, 0.0.0.11)"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Expected, func(t *testing.T) {
+			if s := tc.Input.String(); s != tc.Expected {
+				t.Error("Expected", tc.Expected, "got", s)
+			}
+		})
+	}
+}
+
+func TestNetwork6String(t *testing.T) {
+	for _, s := range []string{"::/0", "2001:db8::/32", "2001:db8::1/128"} {
+		t.Run(s, func(t *testing.T) {
+			if net := bn.ParseNetwork6(s); net.String() != s {
+				t.Error("Expected", s, "got", net.String())
+			}
+		})
+	}
+}
+
+func TestIntervalString(t *testing.T) {
+	type Case struct {
+		Input    bn.Interval
+		Expected string
+	}
+	cases := []Case{
+		{bn.Interval{10<<24 + 5, 10<<24 + 201}, "10.0.0.5-10.0.0.200"},
+		{bn.Interval{1, 2}, "0.0.0.1-0.0.0.1"},
+		{bn.Interval{}, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Expected, func(t *testing.T) {
+			if s := tc.Input.String(); s != tc.Expected {
+				t.Error("Expected", tc.Expected, "got", s)
+			}
+			parsed, err := bn.ParseIntervalE(tc.Expected)
+			if err != nil || parsed != tc.Input {
+				t.Error("Expected", tc.Input, "got", parsed, err)
+			}
+		})
+	}
+}
+
+func TestNetworkMarshalText_Invalid(t *testing.T) {
+	if _, err := (bn.Network{8, 11}).MarshalText(); err == nil {
+		t.Error("Expected error marshaling invalid network")
+	}
+	if _, err := (bn.Interval{2, 1}).MarshalText(); err == nil {
+		t.Error("Expected error marshaling invalid interval")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type Config struct {
+		Networks  []bn.Network
+		Networks6 []bn.Network6
+		Ranges    []bn.Interval
+		Ranges6   []bn.Interval6
+		Prefix    bn.NonEmptyNetwork
+	}
+	config := Config{
+		Networks:  []bn.Network{bn.ParseNetwork("10.0.0.0/8"), bn.ParseNetwork("192.168.1.0/24")},
+		Networks6: []bn.Network6{bn.ParseNetwork6("2001:db8::/32")},
+		Ranges:    []bn.Interval{{10<<24 + 5, 10<<24 + 201}},
+		Ranges6:   []bn.Interval6{bn.Interval6(bn.ParseNetwork6("2001:db8::/120"))},
+		Prefix:    bn.ParseNetwork("172.16.0.0/12").ToNonEmptyNetwork(),
+	}
+	expected := `{"Networks":["10.0.0.0/8","192.168.1.0/24"],` +
+		`"Networks6":["2001:db8::/32"],` +
+		`"Ranges":["10.0.0.5-10.0.0.200"],` +
+		`"Ranges6":["2001:db8::-2001:db8::ff"],` +
+		`"Prefix":"172.16.0.0/12"}`
+
+	b, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if string(b) != expected {
+		t.Error("Expected", expected, "got", string(b))
+	}
+
+	var decoded Config
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if !reflect.DeepEqual(config, decoded) {
+		t.Error("Expected", config, "got", decoded)
+	}
+}
+
+func TestJSON_Error(t *testing.T) {
+	var networks []bn.Network
+	if err := json.Unmarshal([]byte(`["10.0.0.0/33"]`), &networks); err == nil {
+		t.Error("Expected error")
+	}
+}